@@ -1,28 +1,77 @@
 package agent
 
 import (
+	"reflect"
+	"time"
+
+	"github.com/agent-collector/pkg/registers"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	// 采集器在 init() 中注册到 registers 的工厂列表
+	_ "github.com/agent-collector/pkg/collector"
 )
 
 func initMonitorFlags(root *cobra.Command) {
 	f := root.PersistentFlags()
 
 	f.Duration("metrics.interval", defaultCfg.Monitor.Interval, "-> Interval for metrics collection (采集间隔)")
+	f.String("metrics.mode", defaultCfg.Monitor.Mode, "-> Collection mode: push (collect every interval) or scrape (collect when scraped) (采集模式：push 定时采集 / scrape 收到请求时采集)")
+	f.Duration("metrics.scrape_min_age", defaultCfg.Monitor.ScrapeMinAge, "-> In scrape mode, reuse collected data younger than this (scrape 模式下数据的最短复用时间)")
+	f.Int("metrics.stale_cycles", defaultCfg.Monitor.StaleCycles, "-> Delete series not updated for this many collection cycles (序列连续多少个周期未更新后删除)")
+	f.Int("metrics.workers", defaultCfg.Monitor.Workers, "-> Maximum number of collectors running concurrently, 1 runs them one at a time (同时运行的采集器数量上限，1 为串行)")
+	f.Bool("metrics.align", defaultCfg.Monitor.Align, "-> Align collection ticks to wall-clock multiples of the interval (按采集间隔对齐墙上时钟)")
+	f.Duration("metrics.jitter", defaultCfg.Monitor.Jitter, "-> Maximum fixed per-host offset derived from machine-id, capped at the interval, 0 disables (按 machine-id 计算的固定偏移上限，0 为不偏移)")
+	f.Int("metrics.backoff.threshold", defaultCfg.Monitor.Backoff.Threshold, "-> Consecutive failures before a collector backs off (连续失败多少次后开始退避)")
+	f.Duration("metrics.backoff.initial", defaultCfg.Monitor.Backoff.Initial, "-> Initial backoff, doubled after each further failure (首次退避时间，之后每次失败翻倍)")
+	f.Duration("metrics.backoff.max", defaultCfg.Monitor.Backoff.Max, "-> Maximum backoff (最大退避时间)")
+	f.Int("metrics.backoff.disable_after", defaultCfg.Monitor.Backoff.DisableAfter, "-> Disable a collector after this many consecutive failures, 0 never (连续失败多少次后停用采集器，0 为从不停用)")
 
-	f.Bool("collectors.proc.enable", defaultCfg.Monitor.Collectors.Proc.Enable, "-> Enable /proc metrics collector (启用 /proc 采集器)")
-	f.Bool("collectors.proc.collect_per_core", defaultCfg.Monitor.Collectors.Proc.CollectPerCore, "-> Enable per-core metrics collection for /proc (启用 /proc 每个核心的指标采集)")
-	f.Duration("collectors.proc.load_sample_cycle", defaultCfg.Monitor.Collectors.Proc.LoadSampleCycle, "-> Cycle duration for load sampling in /proc collection ( /proc 采集中的负载采样周期)")
-
-	f.Bool("collectors.sys.enable", defaultCfg.Monitor.Collectors.Sys.Enable, "-> Enable /sys metrics collector (启用 /sys 采集器)")
-	f.StringSlice("collectors.sys.ignore-disks", defaultCfg.Monitor.Collectors.Sys.IgnoreDisks, "-> List of disk names to ignore in /sys collection ( /sys 采集中需要忽略的磁盘名称列表)")
-	f.StringSlice("collectors.sys.ignore-networks", defaultCfg.Monitor.Collectors.Sys.IgnoreNetworks, "-> List of network interface names to ignore in /sys collection ( /sys 采集中需要忽略的网卡名称列表)")
+	f.Duration("metrics.init_retry.initial", defaultCfg.Monitor.InitRetry.Initial, "-> Wait before retrying a failed collector init, doubled after each further failure (采集器初始化失败后首次重试的等待时间，之后每次翻倍)")
+	f.Duration("metrics.init_retry.max", defaultCfg.Monitor.InitRetry.Max, "-> Maximum wait between collector init retries (初始化重试的最大间隔)")
+	f.Duration("metrics.init_retry.required_wait", defaultCfg.Monitor.InitRetry.RequiredWait, "-> How long startup waits for a failing required collector before giving up, 0 fails immediately (必需采集器初始化失败时启动阶段的最长等待时间，0 为不等待)")
 
-	f.Bool("collectors.cgroup.enable", defaultCfg.Monitor.Collectors.Cgroup.Enable, "-> Enable cgroup metrics collector (启用 Cgroup 采集器)")
-	f.Bool("collectors.container-runtime.enable", defaultCfg.Monitor.Collectors.Container.Enable, "-> Enable container runtime API collector (启用容器运行时 API 采集器)")
+	initCollectorFlags(f)
 
 	err := viper.BindPFlags(f)
 	if err != nil {
 		return
 	}
 }
+
+// initCollectorFlags 为每个已注册的采集器生成 metrics.collectors.<name>.enable/required，以及其配置结构体中简单类型字段的 Flag（默认值取自工厂）
+// 列表/结构体类型的配置（如 exec.commands、probe.targets）只能在配置文件中配置
+func initCollectorFlags(f *pflag.FlagSet) {
+	for _, factory := range registers.Factories() {
+		prefix := "metrics.collectors." + factory.Name + "."
+		f.Bool(prefix+"enable", factory.DefaultEnabled, "-> Enable collector: "+factory.Description)
+		f.Bool(prefix+"required", false, "-> Fail startup if the collector cannot be initialized (初始化失败时启动失败): "+factory.Name)
+		if factory.NewConfig == nil {
+			continue
+		}
+		v := reflect.ValueOf(factory.NewConfig()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			key := field.Tag.Get("mapstructure")
+			if key == "" || !field.IsExported() {
+				continue
+			}
+			name, usage := prefix+key, "-> "+field.Tag.Get("comment")
+			switch value := v.Field(i).Interface().(type) {
+			case bool:
+				f.Bool(name, value, usage)
+			case string:
+				f.String(name, value, usage)
+			case int:
+				f.Int(name, value, usage)
+			case float64:
+				f.Float64(name, value, usage)
+			case time.Duration:
+				f.Duration(name, value, usage)
+			case []string:
+				f.StringSlice(name, value, usage)
+			}
+		}
+	}
+}
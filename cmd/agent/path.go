@@ -0,0 +1,13 @@
+package agent
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func initPathFlags(root *cobra.Command) {
+	f := root.PersistentFlags()
+
+	f.String("path.procfs", defaultCfg.Path.ProcFS, "-> procfs mountpoint, e.g. /host/proc in a container (procfs 挂载点)")
+	f.String("path.sysfs", defaultCfg.Path.SysFS, "-> sysfs mountpoint, e.g. /host/sys in a container (sysfs 挂载点)")
+	f.String("path.rootfs", defaultCfg.Path.RootFS, "-> Host root filesystem mountpoint, e.g. /host in a container (宿主机根文件系统挂载点)")
+}
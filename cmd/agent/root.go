@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"github.com/agent-collector/cmd/server"
 	"github.com/agent-collector/pkg/config"
+	"github.com/agent-collector/pkg/lifecycle"
 	"github.com/agent-collector/pkg/logger"
 	"github.com/agent-collector/pkg/registers"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 	"os"
+	"time"
 )
 
 var (
@@ -18,6 +21,9 @@ var (
 	GlobalCfg *config.Config
 )
 
+// collectorsStopTimeout 退出时最后一次采集、等待进行中的采集和关闭采集器的总时间
+const collectorsStopTimeout = 10 * time.Second
+
 var rootCmd = &cobra.Command{
 	Use:   "agent-collector",
 	Short: "Production-grade system metrics collector (CPU/disk/network) with Prometheus",
@@ -32,7 +38,7 @@ var rootCmd = &cobra.Command{
 			os.Exit(1) // 退出避免后续 nil 指针 panic
 		}
 		if err := runServer(cmd.Context(), GlobalCfg); err != nil {
-			fmt.Fprintf(os.Stderr, "Service startup failed: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Service failed: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -50,6 +56,7 @@ func init() {
 	initServerFlags(rootCmd)
 	initMonitorFlags(rootCmd)
 	initLogFlags(rootCmd)
+	initPathFlags(rootCmd)
 }
 
 func runServer(ctx context.Context, cfg *config.Config) error {
@@ -71,13 +78,12 @@ func runServer(ctx context.Context, cfg *config.Config) error {
 	defer logger.Sync()
 
 	const enableProcess = true // 直接写死
-	// init Registry
-	registry, _, _ := registers.InitPromRegistry(context.Background(), enableProcess, cfg)
-	httpServer := server.NewHTTPServer(cfg, initLogger, registry)
-	if err := httpServer.Start(); err != nil {
-		return fmt.Errorf("start HTTP server failed: %w", err)
+	// init Registry（采集器已注册，由生命周期管理器启动）
+	registry, agent, err := registers.InitPromRegistry(enableProcess, cfg)
+	if err != nil {
+		return fmt.Errorf("init collectors failed: %w", err)
 	}
-	//return runServer(cmd.Context(), GlobalCfg)
+	httpServer := server.NewHTTPServer(cfg, initLogger, registry, agent)
 	cfgJson, err := json.MarshalIndent(GlobalCfg, "", "  ")
 	if err != nil {
 		logger.Warn("Failed to marshal GlobalCfg to JSON: %v\", err")
@@ -86,18 +92,28 @@ func runServer(ctx context.Context, cfg *config.Config) error {
 		logger.Info(string(cfgJson))
 	}
 
-	server.WaitForShutdown(func() error {
-		//withTimeout, cancelFunc := context.WithTimeout(ctx, 5*time.Second)
-		//defer cancelFunc()
-
-		// 调用HTTP服务的Shutdown方法优雅关闭
-		if err := httpServer.Shutdown(); err != nil {
-			return fmt.Errorf("shutdown HTTP server failed: %w", err)
-		}
-
-		logger.Info("all services shutdown successfully")
-		return nil
-
+	// 按依赖顺序启动、相反顺序停止：collectors -> http；收到 SIGINT/SIGTERM 后先在 PreStop 中做最后一次采集
+	// （此时 HTTP 服务仍在运行，结果可被抓取），再停止 HTTP 服务并关闭所有采集器。
+	// 推送类 sink 应注册为 collectors 的依赖，在最后一次采集之后才停止（flush）
+	lc := lifecycle.New()
+	lc.Add(lifecycle.Component{
+		Name:  "collectors",
+		Start: agent.Start,
+		PreStop: func(ctx context.Context) error {
+			if err := agent.CollectAll(ctx); err != nil {
+				logger.Warn("final collection failed", zap.Error(err))
+			}
+			return nil
+		},
+		Stop:        agent.Shutdown,
+		StopTimeout: collectorsStopTimeout,
+	})
+	lc.Add(lifecycle.Component{
+		Name:        "http",
+		DependsOn:   []string{"collectors"},
+		Start:       func(context.Context) error { return httpServer.Start() },
+		Stop:        httpServer.Shutdown,
+		StopTimeout: server.DefaultShutdownTimeout,
 	})
-	return nil
+	return lc.Run(ctx)
 }
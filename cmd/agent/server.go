@@ -19,4 +19,5 @@ func initServerFlags(root *cobra.Command) {
 	flags.Duration("server.read-timeout", defaultCfg.Server.ReadTimeout, "Read timeout duration (读取超时时间)")
 	flags.Duration("server.write-timeout", defaultCfg.Server.WriteTimeout, "Write timeout duration (写入超时时间)")
 	flags.Duration("server.idle-timeout", defaultCfg.Server.IdleTimeout, "Idle connection timeout duration (空闲连接超时时间)")
+	flags.String("server.api_token", defaultCfg.Server.APIToken, "Bearer token required by collector management API mutations, empty disables them (管理接口修改操作所需的 Bearer Token，为空时禁止修改)")
 }
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/agent-collector/pkg/registers"
+	"go.uber.org/zap"
+)
+
+// apiError 管理接口的错误响应
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// registerCollectorAPI 注册采集器管理接口
+// GET  /api/v1/collectors                 列出所有采集器（状态、间隔、最近一次耗时和错误）
+// GET  /api/v1/collectors/{name}          单个采集器
+// POST /api/v1/collectors/{name}/enable   启用（需要认证）
+// POST /api/v1/collectors/{name}/disable  停用（需要认证）
+// POST /api/v1/collectors/{name}/trigger  立即采集一次并返回结果（需要认证，最长等待 triggerTimeout，超时计为采集失败）
+func (s *Server) registerCollectorAPI() {
+	s.mux.HandleFunc("GET /api/v1/collectors", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"collectors": s.agent.Collectors()})
+	})
+	s.mux.HandleFunc("GET /api/v1/collectors/{name}", func(w http.ResponseWriter, r *http.Request) {
+		status, err := s.agent.Collector(r.PathValue("name"))
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	})
+	s.mux.Handle("POST /api/v1/collectors/{name}/enable", s.requireToken(s.setEnabledHandler(true)))
+	s.mux.Handle("POST /api/v1/collectors/{name}/disable", s.requireToken(s.setEnabledHandler(false)))
+	s.mux.Handle("POST /api/v1/collectors/{name}/trigger", s.requireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		ctx, cancel := context.WithTimeout(r.Context(), triggerTimeout)
+		defer cancel()
+		err := s.agent.TriggerCollector(ctx, name)
+		s.auditLog(r, "trigger", name, err)
+		if err != nil && (errors.Is(err, registers.ErrCollectorNotFound) || errors.Is(err, registers.ErrCollectorBusy) || errors.Is(err, registers.ErrCollectorDisabled) ||
+			errors.Is(err, registers.ErrCollectorNotInitialized) || errors.Is(err, registers.ErrAgentStopped)) {
+			writeAPIError(w, err)
+			return
+		}
+		status, _ := s.agent.Collector(name)
+		// 采集本身失败时仍返回 200，错误体现在 last_error 中
+		writeJSON(w, http.StatusOK, status)
+	})))
+}
+
+// setEnabledHandler 启用/停用采集器
+func (s *Server) setEnabledHandler(enabled bool) http.Handler {
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		err := s.agent.SetCollectorEnabled(name, enabled)
+		s.auditLog(r, action, name, err)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		status, _ := s.agent.Collector(name)
+		writeJSON(w, http.StatusOK, status)
+	})
+}
+
+// requireToken 修改操作需要 Authorization: Bearer <server.api_token>；未配置 token 时禁止修改
+func (s *Server) requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := s.cfg.Server.APIToken
+		if token == "" {
+			s.auditLog(r, "denied", r.PathValue("name"), errors.New("api token not configured"))
+			writeJSON(w, http.StatusForbidden, apiError{Error: "management API is read-only: server.api_token is not configured"})
+			return
+		}
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			s.auditLog(r, "denied", r.PathValue("name"), errors.New("invalid or missing bearer token"))
+			w.Header().Set("WWW-Authenticate", `Bearer realm="agent-collector"`)
+			writeJSON(w, http.StatusUnauthorized, apiError{Error: "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// auditLog 记录管理接口的修改操作（包括被拒绝的请求）
+func (s *Server) auditLog(r *http.Request, action, collector string, err error) {
+	fields := []zap.Field{
+		zap.String("action", action),
+		zap.String("collector", collector),
+		zap.String("remote", r.RemoteAddr),
+		zap.String("user_agent", r.UserAgent()),
+	}
+	if err != nil {
+		s.logger.Warn("collector management request failed", append(fields, zap.Error(err))...)
+		return
+	}
+	s.logger.Info("collector management request", fields...)
+}
+
+// writeAPIError 按错误类型映射 HTTP 状态码
+func writeAPIError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, registers.ErrCollectorNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, registers.ErrCollectorBusy), errors.Is(err, registers.ErrCollectorDisabled), errors.Is(err, registers.ErrCollectorNotInitialized):
+		status = http.StatusConflict
+	case errors.Is(err, registers.ErrAgentStopped):
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, apiError{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/config"
+	"github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+	"github.com/agent-collector/pkg/registers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TestMain 服务和调度器依赖全局 logger，测试前先初始化到临时目录
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "server-test-logs")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if _, err := logger.InitLogger(&config.ZapLogConfig{Level: "error", Format: "json", Path: dir}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+type stubCollector struct {
+	calls    int
+	deadline time.Duration // 最近一次 Collect 收到的 ctx 剩余时间
+}
+
+func (c *stubCollector) Name() string { return "stub" }
+func (c *stubCollector) Init() error  { return nil }
+func (c *stubCollector) Close() error { return nil }
+func (c *stubCollector) Collect(ctx context.Context) error {
+	c.calls++
+	if d, ok := ctx.Deadline(); ok {
+		c.deadline = time.Until(d)
+	}
+	return errors.New("stub failure")
+}
+
+func newAPITestServer(t *testing.T, token string) (*httptest.Server, *stubCollector) {
+	t.Helper()
+	cfg := config.NewDefaultConfig()
+	cfg.Server.APIToken = token
+	cfg.Monitor.Interval = time.Minute // 采集超时默认等于间隔，远大于 triggerTimeout
+	reg := prometheus.NewRegistry()
+	agent := registers.NewRegistry(&cfg.Monitor, *metrics.NewMetricFactory(metrics.NewPromRegistry(reg)), procfs.OSFS{})
+	stub := &stubCollector{}
+	agent.Register(stub)
+
+	srv := NewHTTPServer(cfg, logger.GetGlobalLogger(), reg, agent)
+	ts := httptest.NewServer(srv.server.Handler)
+	t.Cleanup(ts.Close)
+	return ts, stub
+}
+
+func doRequest(t *testing.T, method, url, token string) (int, registers.CollectorStatus) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var status registers.CollectorStatus
+	_ = json.NewDecoder(resp.Body).Decode(&status)
+	return resp.StatusCode, status
+}
+
+func TestCollectorAPI(t *testing.T) {
+	ts, stub := newAPITestServer(t, "secret")
+	base := ts.URL + "/api/v1/collectors"
+
+	resp, err := http.Get(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list struct {
+		Collectors []registers.CollectorStatus `json:"collectors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(list.Collectors) != 1 || list.Collectors[0].Name != "stub" || list.Collectors[0].State != registers.StateHealthy {
+		t.Fatalf("list = %+v, want one healthy stub collector", list.Collectors)
+	}
+
+	if code, _ := doRequest(t, http.MethodPost, base+"/stub/disable", ""); code != http.StatusUnauthorized {
+		t.Errorf("disable without token: status %d, want 401", code)
+	}
+	if code, _ := doRequest(t, http.MethodPost, base+"/stub/disable", "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("disable with wrong token: status %d, want 401", code)
+	}
+	if code, _ := doRequest(t, http.MethodPost, base+"/missing/disable", "secret"); code != http.StatusNotFound {
+		t.Errorf("disable unknown collector: status %d, want 404", code)
+	}
+
+	code, status := doRequest(t, http.MethodPost, base+"/stub/disable", "secret")
+	if code != http.StatusOK || status.State != registers.StateDisabled {
+		t.Fatalf("disable: status %d state %q, want 200 disabled", code, status.State)
+	}
+	if code, _ := doRequest(t, http.MethodPost, base+"/stub/trigger", "secret"); code != http.StatusConflict {
+		t.Errorf("trigger disabled collector: status %d, want 409", code)
+	}
+
+	if code, status = doRequest(t, http.MethodPost, base+"/stub/enable", "secret"); code != http.StatusOK || status.State != registers.StateHealthy {
+		t.Fatalf("enable: status %d state %q, want 200 healthy", code, status.State)
+	}
+	code, status = doRequest(t, http.MethodPost, base+"/stub/trigger", "secret")
+	if code != http.StatusOK || stub.calls != 1 {
+		t.Fatalf("trigger: status %d calls %d, want 200 and 1 call", code, stub.calls)
+	}
+	if status.LastError != "stub failure" || status.State != registers.StateDegraded || status.LastRun == nil ||
+		time.Since(*status.LastRun) > time.Minute {
+		t.Errorf("trigger result = %+v, want degraded with last_error and last_run", status)
+	}
+	if stub.deadline <= 0 || stub.deadline > triggerTimeout || triggerTimeout >= writeTimeout {
+		t.Errorf("triggered collection deadline = %v, want capped at %v below write timeout %v", stub.deadline, triggerTimeout, writeTimeout)
+	}
+}
+
+func TestCollectorAPIReadOnlyWithoutToken(t *testing.T) {
+	ts, _ := newAPITestServer(t, "")
+	if code, _ := doRequest(t, http.MethodPost, ts.URL+"/api/v1/collectors/stub/disable", "anything"); code != http.StatusForbidden {
+		t.Errorf("disable without configured token: status %d, want 403", code)
+	}
+	if code, status := doRequest(t, http.MethodGet, ts.URL+"/api/v1/collectors/stub", ""); code != http.StatusOK || status.Name != "stub" {
+		t.Errorf("get collector: status %d name %q, want 200 stub", code, status.Name)
+	}
+}
@@ -7,14 +7,13 @@ import (
 	"github.com/agent-collector/pkg/config"
 	"github.com/agent-collector/pkg/logger"
 	log "github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/registers"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
 	"sync"
-	"syscall"
 	"time"
 )
 
@@ -24,6 +23,7 @@ type Server struct {
 	logger   *logger.Logger
 	server   *http.Server
 	registry *prometheus.Registry
+	agent    registers.Agent // 采集器管理接口的后端，为 nil 时不注册 /api/v1/collectors
 	mux      *customMux
 }
 
@@ -40,7 +40,14 @@ type customMux struct {
 	mu     sync.Mutex
 }
 
-const defaultShutdownTimeout = 5 * time.Second
+// DefaultShutdownTimeout 等待进行中的 HTTP 请求结束的时间
+const DefaultShutdownTimeout = 5 * time.Second
+
+const (
+	writeTimeout = 10 * time.Second
+	// triggerTimeout 手动触发采集的最长等待时间，留出余量保证在 writeTimeout 之前写回响应
+	triggerTimeout = writeTimeout - 2*time.Second
+)
 
 // Handle 重写Handle，注册路由时记录路径
 func (m *customMux) Handle(pattern string, handler http.Handler) {
@@ -64,24 +71,28 @@ func (m *customMux) HandleFunc(pattern string, handler func(http.ResponseWriter,
 }
 
 // NewHTTPServer 创建HTTP服务实例
-func NewHTTPServer(cfg *config.Config, logger *logger.Logger, registry *prometheus.Registry) *Server {
+func NewHTTPServer(cfg *config.Config, logger *logger.Logger, registry *prometheus.Registry, agent registers.Agent) *Server {
 	mux := &customMux{}
 
 	srv := &Server{
 		cfg:      cfg,
 		logger:   logger,
 		registry: registry,
+		agent:    agent,
 		mux:      mux,
 	}
 
 	// 注册核心端点
 	srv.registerEndpoints()
+	if agent != nil {
+		srv.registerCollectorAPI()
+	}
 
 	srv.server = &http.Server{
 		Addr:         cfg.Server.Addr,
 		Handler:      srv.logMiddleware(mux),
 		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		WriteTimeout: writeTimeout,
 		IdleTimeout:  15 * time.Second,
 	}
 
@@ -134,6 +145,7 @@ func (s *Server) registerEndpoints() {
 			<h2>Available Endpoints:</h2>
 			<a href="/health">/health - 健康检查</a>
 			<a href="/metrics">/metrics - Prometheus 指标暴露</a>
+			<a href="/api/v1/collectors">/api/v1/collectors - 采集器状态</a>
 		</body>
 		</html>
 		`)
@@ -141,8 +153,10 @@ func (s *Server) registerEndpoints() {
 	})
 
 	// /metrics 端点
+	// ContinueOnError：外部合并的指标（如 textfile）与内置指标冲突时，只丢弃冲突部分而不是整个 scrape 失败
 	s.mux.Handle("/metrics", promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{
-		ErrorLog: zap.NewStdLog(log.GetGlobalLogger()),
+		ErrorLog:      zap.NewStdLog(log.GetGlobalLogger()),
+		ErrorHandling: promhttp.ContinueOnError,
 	}))
 
 	// /health 端点
@@ -158,7 +172,7 @@ func (w *statusWriter) WriteHeader(statusCode int) {
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
-// Start 启动HTTP服务（非阻塞）
+// Start 启动HTTP服务（非阻塞），监听地址被占用等错误直接返回
 func (s *Server) Start() error {
 	s.logger.Info(
 		"starting HTTP server",
@@ -167,24 +181,21 @@ func (s *Server) Start() error {
 		zap.String("采集间隔", s.cfg.Monitor.Interval.String()),
 		zap.String("log_path", s.cfg.Log.Path), zap.String("log_level", s.cfg.Log.Level), zap.String("log_format", s.cfg.Log.Format),
 	)
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", s.server.Addr, err)
+	}
 	go func() {
-		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			s.logger.Error("HTTP server failed", zap.Error(err))
 		}
 	}()
 	return nil
 }
 
-// Shutdown 优雅关闭HTTP服务
-func (s *Server) Shutdown() error {
-	ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
-	defer cancel()
-
+// Shutdown 优雅关闭HTTP服务：停止接受新连接，等待进行中的请求结束直到 ctx 到期
+func (s *Server) Shutdown(ctx context.Context) error {
 	if err := s.server.Shutdown(ctx); err != nil {
-		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-			s.logger.Warn("shutdown timeout exceeded")
-			return nil
-		}
 		s.logger.Error("HTTP server shutdown failed", zap.Error(err))
 		return err
 	}
@@ -192,32 +203,3 @@ func (s *Server) Shutdown() error {
 	s.logger.Info("HTTP server shutdown successfully")
 	return nil
 }
-
-// WaitForShutdown 监听退出信号
-func WaitForShutdown(shutdownFunc func() error) {
-	if shutdownFunc == nil {
-		log.Error("shutdownFunc is nil, cannot execute shutdown")
-		return
-	}
-
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	defer signal.Stop(sigChan)
-
-	log.Info("service running, waiting for SIGINT/SIGTERM...")
-
-	sig := <-sigChan
-	log.Info("received shutdown signal", zap.String("signal", sig.String()))
-
-	if err := shutdownFunc(); err != nil {
-		log.Error("graceful shutdown failed", zap.Error(err))
-	} else {
-		log.Info("graceful shutdown completed successfully")
-	}
-
-	if err := log.Sync(); err != nil && err.Error() != "sync /dev/stdout: bad file descriptor" {
-		log.Warn("logger sync failed", zap.Error(err))
-	}
-
-	log.Info("shutdown workflow finished, exiting program")
-}
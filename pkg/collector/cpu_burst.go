@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/procfs"
+	"go.uber.org/zap"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CPUBurstStats 单个 CPU 在一个采集窗口内的高频采样统计
+type CPUBurstStats struct {
+	Max            float64       // 窗口内最大使用率（%）
+	P95            float64       // 窗口内使用率 p95（%）
+	AboveThreshold time.Duration // 窗口内使用率不低于阈值的累计时间
+	Samples        int           // 窗口内有效样本数
+}
+
+// burstWindow 单个 CPU 当前窗口的样本
+type burstWindow struct {
+	samples []float64
+	above   time.Duration
+}
+
+// burstBaseline 单个 CPU 上一次采样的时间及其所在的采样轮次
+type burstBaseline struct {
+	times CPUTimes
+	round uint64
+}
+
+// CPUBurstSampler CPU 突发检测采样器
+// 以远小于采集间隔的周期（如 100ms）读取 /proc/stat，复用 cpuDelta 计算每个采样区间的使用率，
+// 每次 Flush 输出上一个采集窗口内的 max/p95/超阈值时间，避免短时打满被采集间隔平均掉
+// 高频采样不经过周期快照，使用自己的缓冲区和标签缓存，稳定运行后不再分配内存
+type CPUBurstSampler struct {
+	interval  time.Duration
+	threshold float64 // 使用率阈值（%）
+	fs        procfs.FS
+
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	labels  cpuLabelCache
+	last    map[string]*burstBaseline
+	lastAt  time.Time
+	round   uint64
+	windows map[string]*burstWindow
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewCPUBurstSampler 创建突发检测采样器
+func NewCPUBurstSampler(interval time.Duration, threshold float64, fsys procfs.FS) (*CPUBurstSampler, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("burst sample interval must be positive (got %v)", interval)
+	}
+	return &CPUBurstSampler{
+		interval:  interval,
+		threshold: threshold,
+		fs:        fsys,
+		labels:    make(cpuLabelCache),
+		last:      make(map[string]*burstBaseline),
+		windows:   make(map[string]*burstWindow),
+	}, nil
+}
+
+// Start 启动后台采样协程(非阻塞)
+func (s *CPUBurstSampler) Start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			if err := s.sample(time.Now()); err != nil {
+				logger.Warn("cpu burst sampler sample failed", zap.Error(err))
+			}
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台采样并等待协程退出
+func (s *CPUBurstSampler) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
+
+// sample 读取一次 /proc/stat，与上一次采样做差得到各 CPU 在该区间的使用率
+func (s *CPUBurstSampler) sample(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := procfs.ReadFile(context.Background(), s.fs, procfs.ProcPath("stat"), &s.buf)
+	if err != nil {
+		return fmt.Errorf("read /proc/stat: %w", err)
+	}
+	s.record(data, now)
+	return nil
+}
+
+// record 记录一次采样结果（调用方持有锁）
+func (s *CPUBurstSampler) record(data []byte, now time.Time) {
+	elapsed := now.Sub(s.lastAt)
+	s.round++
+	parseProcStat(data, func(raw []byte, times CPUTimes) {
+		id := s.labels.get(raw).id
+		base, exists := s.last[id]
+		if !exists {
+			s.last[id] = &burstBaseline{times: times, round: s.round}
+			return
+		}
+		if deltas, deltaTotal, ok := cpuDelta(base.times, times); ok {
+			w := s.windows[id]
+			if w == nil {
+				w = &burstWindow{}
+				s.windows[id] = w
+			}
+			usage := busyPercent(deltas, deltaTotal)
+			w.samples = append(w.samples, usage)
+			if usage >= s.threshold {
+				w.above += elapsed
+			}
+		}
+		base.times = maxCPUTimes(base.times, times)
+		base.round = s.round
+	})
+	s.lastAt = now
+
+	// 下线的 CPU 不再出现在 /proc/stat 中，清理其历史基准
+	for id, base := range s.last {
+		if base.round != s.round {
+			delete(s.last, id)
+		}
+	}
+}
+
+// Flush 返回当前窗口的统计并开始新窗口；窗口内没有样本的 CPU 不出现在结果中
+func (s *CPUBurstSampler) Flush() map[string]CPUBurstStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[string]CPUBurstStats, len(s.windows))
+	for id, w := range s.windows {
+		if len(w.samples) == 0 {
+			delete(s.windows, id)
+			continue
+		}
+		sort.Float64s(w.samples)
+		result[id] = CPUBurstStats{
+			Max:            w.samples[len(w.samples)-1],
+			P95:            percentile(w.samples, 0.95),
+			AboveThreshold: w.above,
+			Samples:        len(w.samples),
+		}
+		// 复用底层数组，避免每个窗口重新分配
+		w.samples = w.samples[:0]
+		w.above = 0
+	}
+	return result
+}
+
+// percentile 最近秩法计算已排序样本的分位数
+func percentile(sorted []float64, q float64) float64 {
+	idx := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
@@ -0,0 +1,100 @@
+package collector_test
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/agent-collector/pkg/collector"
+	"github.com/agent-collector/pkg/procfs"
+)
+
+// burstStat 生成 /proc/stat 内容，times 为 cpu 名 -> {user, idle}（单位 USER_HZ）
+func burstStat(cpus []string, times map[string][2]int) []byte {
+	var b strings.Builder
+	for _, cpu := range cpus {
+		t := times[cpu]
+		fmt.Fprintf(&b, "%s %d 0 0 %d 0 0 0 0 0 0\n", cpu, t[0], t[1])
+	}
+	b.WriteString("procs_running 1\n")
+	return []byte(b.String())
+}
+
+// approxEqual 使用率由浮点除法得到，比较时允许舍入误差
+func approxEqual(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+// TestCPUBurstSampler 注入确定的 /proc/stat 序列，验证 max/p95/超阈值时间，以及 CPU 下线后基准被丢弃
+func TestCPUBurstSampler(t *testing.T) {
+	const interval = 100 * time.Millisecond
+	fsys := procfs.MapFS{}
+	s, err := collector.NewCPUBurstSampler(interval, 80, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1760745600, 0)
+	times := map[string][2]int{"cpu0": {0, 0}, "cpu1": {1000, 1000}}
+	sample := func(cpus ...string) {
+		t.Helper()
+		fsys["/proc/stat"] = &fstest.MapFile{Data: burstStat(cpus, times)}
+		if err := s.SampleAt(now); err != nil {
+			t.Fatalf("sample: %v", err)
+		}
+		now = now.Add(interval)
+	}
+	// advance 让 cpu 在下一个区间内的使用率为 busy%（每个区间 100 个 tick）
+	advance := func(cpu string, busy int) {
+		t := times[cpu]
+		times[cpu] = [2]int{t[0] + busy, t[1] + 100 - busy}
+	}
+
+	// 窗口 1：cpu0 17 个 10% 区间加 50/90/100 三个尖峰；cpu1 第一次采样后下线
+	sample("cpu0", "cpu1")
+	for _, busy := range []int{10, 10, 10, 10, 10, 10, 10, 10, 50, 10, 10, 10, 10, 90, 10, 10, 10, 10, 100, 10} {
+		advance("cpu0", busy)
+		sample("cpu0")
+	}
+	stats := s.Flush()
+	cpu0, ok := stats["cpu0"]
+	if !ok {
+		t.Fatalf("no stats for cpu0: %+v", stats)
+	}
+	if cpu0.Samples != 20 {
+		t.Errorf("cpu0 samples = %d, want 20", cpu0.Samples)
+	}
+	if !approxEqual(cpu0.Max, 100) {
+		t.Errorf("cpu0 max = %v, want 100", cpu0.Max)
+	}
+	// 最近秩法：ceil(0.95*20) = 19，第 19 小的样本是 90
+	if !approxEqual(cpu0.P95, 90) {
+		t.Errorf("cpu0 p95 = %v, want 90", cpu0.P95)
+	}
+	if cpu0.AboveThreshold != 2*interval {
+		t.Errorf("cpu0 above threshold = %v, want %v (90%% and 100%% intervals)", cpu0.AboveThreshold, 2*interval)
+	}
+	if _, ok := stats["cpu1"]; ok {
+		t.Errorf("cpu1 has stats without any interval sampled: %+v", stats["cpu1"])
+	}
+
+	// 窗口 2：cpu1 重新上线且计数器从头开始，旧基准必须已丢弃，否则倒退的计数器得不到样本
+	times["cpu1"] = [2]int{10, 10}
+	sample("cpu0", "cpu1")
+	advance("cpu0", 0)
+	advance("cpu1", 50)
+	sample("cpu0", "cpu1")
+	stats = s.Flush()
+	if got := stats["cpu1"]; got.Samples != 1 || !approxEqual(got.Max, 50) || !approxEqual(got.P95, 50) || got.AboveThreshold != 0 {
+		t.Errorf("cpu1 after coming back online = %+v, want one 50%% sample", got)
+	}
+	if got := stats["cpu0"]; got.Samples != 1 || !approxEqual(got.Max, 0) {
+		t.Errorf("cpu0 second window = %+v, want one idle sample", got)
+	}
+
+	// 窗口 3：没有新样本的 CPU 不出现在结果中
+	if stats := s.Flush(); len(stats) != 0 {
+		t.Errorf("empty window flushed %+v, want no stats", stats)
+	}
+}
@@ -1,16 +1,17 @@
 package collector
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"fmt"
-	"github.com/agent-collector/pkg/config"
 	"github.com/agent-collector/pkg/logger"
 	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+	"github.com/agent-collector/pkg/registers"
 	"github.com/shirou/gopsutil/v3/cpu"
-	"os"
+	"math"
+	"runtime"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -18,74 +19,235 @@ import (
 	"go.uber.org/zap"
 )
 
-// CPUTimes 存储CPU各模式的累计时间
+// CPUTimes 存储CPU各模式的累计时间（单位：秒，已按 USER_HZ 换算）
+// 内核统计的 user/nice 已包含 guest/guest_nice，解析时已扣除，各模式之间互不重叠
 type CPUTimes struct {
-	User    float64
-	Nice    float64
-	System  float64
-	Idle    float64
-	Iowait  float64
-	Irq     float64
-	Softirq float64
-	Steal   float64
+	User      float64
+	Nice      float64
+	System    float64
+	Idle      float64
+	Iowait    float64
+	Irq       float64
+	Softirq   float64
+	Steal     float64
+	Guest     float64
+	GuestNice float64
+}
+
+// cpuModes 导出的 CPU 模式（与 /proc/stat 字段顺序一致）
+var cpuModes = []string{"user", "nice", "system", "idle", "iowait", "irq", "softirq", "steal", "guest", "guest_nice"}
+
+// values 按 cpuModes 顺序返回各模式时间
+func (t CPUTimes) values() [10]float64 {
+	return [10]float64{t.User, t.Nice, t.System, t.Idle, t.Iowait, t.Irq, t.Softirq, t.Steal, t.Guest, t.GuestNice}
+}
+
+// Total 所有模式时间之和（各模式互不重叠，可直接相加）
+func (t CPUTimes) Total() float64 {
+	total := 0.0
+	for _, v := range t.values() {
+		total += v
+	}
+	return total
+}
+
+// parseCPUTimes 解析 /proc/stat 中 cpu 行标识之后的时间字段（单位为 USER_HZ）
+// 动态适配字段数量差异（老内核/ARM 可能缺少 steal/guest），缺失字段默认0.0；少于4个字段时返回 false
+// 字段顺序（Linux标准）：user(1) → nice(2) → system(3) → idle(4) → iowait(5) → irq(6) → softirq(7) → steal(8) → guest(9) → guest_nice(10)
+func parseCPUTimes(rest []byte) (CPUTimes, bool) {
+	var raw [10]float64
+	n := 0
+	for n < len(raw) {
+		var field []byte
+		field, rest = procfs.NextField(rest)
+		if len(field) == 0 {
+			break
+		}
+		v, _ := procfs.ParseUint(field)
+		raw[n] = float64(v) / cpu.ClocksPerSec
+		n++
+	}
+	// 至少需要 user/nice/system/idle 4个基础时间字段
+	if n < 4 {
+		return CPUTimes{}, false
+	}
+	times := CPUTimes{
+		User: raw[0], Nice: raw[1], System: raw[2], Idle: raw[3], Iowait: raw[4],
+		Irq: raw[5], Softirq: raw[6], Steal: raw[7], Guest: raw[8], GuestNice: raw[9],
+	}
+	// user/nice 已包含 guest/guest_nice，扣除后避免重复计算
+	times.User = math.Max(times.User-times.Guest, 0)
+	times.Nice = math.Max(times.Nice-times.GuestNice, 0)
+	return times, true
+}
+
+// parseProcStat 逐行解析 /proc/stat 中的 cpu 行（兼容ARM/x86），按文件中的顺序回调
+// id 为原始标识（"cpu" / "cpuN"），只在回调内有效
+func parseProcStat(data []byte, fn func(id []byte, times CPUTimes)) {
+	for len(data) > 0 {
+		var line []byte
+		line, data = procfs.NextLine(data)
+		if !procfs.HasPrefix(line, "cpu") {
+			continue
+		}
+		id, rest := procfs.NextField(line)
+		if times, ok := parseCPUTimes(rest); ok {
+			fn(id, times)
+		}
+	}
+}
+
+// cpuLabel 同一个 CPU 在各指标中使用的标签值
+type cpuLabel struct {
+	id    string // cpu_seconds_total 等使用：total / cpuN
+	ratio string // cpu_usage_ratio 使用：total / cpu-N
+}
+
+// cpuLabelCache 缓存 /proc/stat 原始标识对应的标签，每个周期不再重复分配字符串
+type cpuLabelCache map[string]cpuLabel
+
+func (m cpuLabelCache) get(raw []byte) cpuLabel {
+	if l, ok := m[string(raw)]; ok {
+		return l
+	}
+	l := cpuLabel{id: string(raw), ratio: "cpu-" + string(raw[3:])}
+	if l.id == "cpu" {
+		l = cpuLabel{id: "total", ratio: "total"} // 总览行重命名为total，统一标签格式
+	}
+	m[string(raw)] = l
+	return l
+}
+
+// ProcConfig CPU 采集器配置（collectors.proc）
+type ProcConfig struct {
+	CollectPerCore      bool          `yaml:"collect_per_core" mapstructure:"collect_per_core" comment:"是否按每核心采集CPU指标"`
+	LoadMode            string        `yaml:"load_mode" mapstructure:"load_mode" validate:"omitempty,oneof=kernel instant_run_queue" comment:"负载模式：kernel（内核load average）/instant_run_queue（procs_running滑动平均）"`
+	LoadSampleCycle     time.Duration `yaml:"load_sample_cycle" mapstructure:"load_sample_cycle" comment:"运行队列采样周期（仅 instant_run_queue 模式）"`
+	BurstSampleInterval time.Duration `yaml:"burst_sample_interval" mapstructure:"burst_sample_interval" comment:"CPU突发检测采样周期（如100ms，0为关闭）"`
+	BurstThreshold      float64       `yaml:"burst_threshold" mapstructure:"burst_threshold" comment:"CPU突发阈值（使用率百分比）"`
+}
+
+// Validate 负载模式为空时使用 kernel；突发检测采样周期为 0 表示关闭，开启时周期不能过小，阈值必须是合法百分比
+func (c *ProcConfig) Validate() error {
+	if c.LoadMode == "" {
+		c.LoadMode = LoadModeKernel
+	}
+	if c.LoadMode != LoadModeKernel && c.LoadMode != LoadModeInstantRunQueue {
+		return fmt.Errorf("proc.load_mode must be kernel or instant_run_queue, got %q", c.LoadMode)
+	}
+	if c.BurstSampleInterval == 0 {
+		return nil
+	}
+	if c.BurstSampleInterval < 10*time.Millisecond {
+		return fmt.Errorf("proc.burst_sample_interval must be at least 10ms, got %s", c.BurstSampleInterval)
+	}
+	if c.BurstThreshold <= 0 || c.BurstThreshold > 100 {
+		return fmt.Errorf("proc.burst_threshold must be in (0, 100], got %v", c.BurstThreshold)
+	}
+	return nil
+}
+
+func init() {
+	registers.RegisterFactory(registers.Factory{
+		Name:           "proc",
+		Description:    "/proc CPU usage and load metrics (/proc CPU 使用率与负载指标)",
+		DefaultEnabled: true,
+		NewConfig: func() any {
+			return &ProcConfig{CollectPerCore: true, LoadMode: LoadModeKernel, LoadSampleCycle: time.Second, BurstThreshold: 90}
+		},
+		New: func(cfg any, deps registers.Deps) (registers.Collector, error) {
+			return NewCPUCollector(cfg.(*ProcConfig), deps.MetricFactory, deps.FS), nil
+		},
+	})
 }
 
 // CPUCollector CPU采集器（实现Collector接口）
 type CPUCollector struct {
 	name            string
-	cfg             *config.CollectorConfig
+	cfg             *ProcConfig
 	metrics         metrics.CPUCollectorMetrics
 	collectErrors   *prometheus.CounterVec
 	collectDuration *prometheus.HistogramVec
-
-	loadCalculator *LoadCalculator // 负载计算器实例
-	stopLoadSample func()          // 负载采样停止函数
-
-	cpuInfoInitialized bool                // 用来防止重复采集 CPU 静态信息，提升程序效率。
-	lastCPUTimes       map[string]CPUTimes // 存储上一次的CPU时间，用于计算使用率
-
-	cpuId         string              // 逻辑CPU序号（processor字段）
-	modelName     string              // 型号名称
-	coreID        string              // 物理核心ID（core id字段，ARM用）
-	logicalCores  int64               // 逻辑核心数（processor最大序号+1）
-	coreIDSet     map[string]struct{} // 去重存储coreID，统计物理核心数
-	hasCPUCores   bool                // 是否存在cpu cores字段（x86架构）
-	physicalCores int64               // 物理核心数
-	finalCores    int64
+	fs              procfs.FS // /proc、/sys 读取入口
+
+	loadCalculator *LoadCalculator  // 负载计算器实例
+	stopLoadSample func()           // 负载采样停止函数
+	burstSampler   *CPUBurstSampler // CPU 突发检测采样器（未开启时为 nil）
+
+	cpuInfoInitialized bool                 // 用来防止重复采集 CPU 静态信息，提升程序效率。
+	lastCPUTimes       map[string]CPUTimes  // 存储上一次的CPU时间，用于计算使用率
+	series             *metrics.SeriesScope // 跟踪每轮写入的序列，CPU 下线后删除过期序列
+	labels             cpuLabelCache        // /proc/stat 标识 -> 标签值
+	statBuf            bytes.Buffer         // 没有快照时（单独调用 Collect）读取 /proc/stat 的缓冲区
+	loadBuf            bytes.Buffer         // 同上，/proc/loadavg
+	onlineBuf          bytes.Buffer         // 同上，/sys/devices/system/cpu/online
 }
 
 // NewCPUCollector 创建CPU采集器
-func NewCPUCollector(cfg *config.CollectorConfig, metricFactory metrics.MetricFactory) *CPUCollector {
-	calculator, err := NewLoadCalculator(cfg.Proc.LoadSampleCycle)
-	if err != nil {
-		logger.Error("failed to create load calculator", zap.Error(err))
-		//	非致命错误,继续创建采集器
-		calculator = nil
-	}
-	return &CPUCollector{
-		name:          "cpu-collector",
-		cfg:           cfg,
-		lastCPUTimes:  make(map[string]CPUTimes),
-		cpuId:         "",
-		modelName:     "",
-		coreID:        "0",
-		coreIDSet:     make(map[string]struct{}),
-		hasCPUCores:   false,
-		physicalCores: 0,
-		finalCores:    0,
+func NewCPUCollector(cfg *ProcConfig, metricFactory metrics.MetricFactory, fsys procfs.FS) *CPUCollector {
+	// 只有 instant_run_queue 模式需要后台采样的负载计算器，kernel 模式直接读取 /proc/loadavg
+	var calculator *LoadCalculator
+	var err error
+	if cfg.LoadMode == LoadModeInstantRunQueue {
+		calculator, err = NewLoadCalculator(cfg.LoadSampleCycle, fsys)
+		if err != nil {
+			logger.Error("failed to create load calculator", zap.Error(err))
+			//	非致命错误,继续创建采集器
+			calculator = nil
+		}
+	}
+	var burstSampler *CPUBurstSampler
+	if cfg.BurstSampleInterval > 0 {
+		burstSampler, err = NewCPUBurstSampler(cfg.BurstSampleInterval, cfg.BurstThreshold, fsys)
+		if err != nil {
+			logger.Error("failed to create cpu burst sampler", zap.Error(err))
+			burstSampler = nil
+		}
+	}
+	c := &CPUCollector{
+		name:         "cpu-collector",
+		cfg:          cfg,
+		fs:           fsys,
+		lastCPUTimes: make(map[string]CPUTimes),
+		labels:       make(cpuLabelCache),
+		series:       metricFactory.SeriesTracker().Scope("cpu-collector"),
 		metrics: metrics.CPUCollectorMetrics{
-			UsageRatio:       metricFactory.NewCPUUsageRatio(),
-			Load1:            metricFactory.NewCPULoad1(),
-			Load5:            metricFactory.NewCPULoad5(),
-			Load15:           metricFactory.NewCPULoad15(),
-			UsagePercent:     metricFactory.NewCPUUsagePercent(),
-			UsageModePercent: metricFactory.NewCPUUsageModePercent(),
-			CPUInfo:          metricFactory.NewCPUInfo(),
+			UsageRatio:        metricFactory.NewCPUUsageRatio(),
+			UsagePercent:      metricFactory.NewCPUUsagePercent(),
+			UsageModePercent:  metricFactory.NewCPUUsageModePercent(),
+			SecondsTotal:      metricFactory.NewCPUSecondsTotal(),
+			CPUInfo:           metricFactory.NewCPUInfo(),
+			Packages:          metricFactory.NewCPUPackages(),
+			PhysicalCores:     metricFactory.NewCPUPhysicalCores(),
+			LogicalCPUs:       metricFactory.NewCPULogicalCPUs(),
+			MicrocodeInfo:     metricFactory.NewCPUMicrocodeInfo(),
+			FlagInfo:          metricFactory.NewCPUFlagInfo(),
+			VulnerabilityInfo: metricFactory.NewCPUVulnerabilityInfo(),
+			BurstMax:          metricFactory.NewCPUBurstUsageMaxPercent(),
+			BurstP95:          metricFactory.NewCPUBurstUsageP95Percent(),
+			BurstAbove:        metricFactory.NewCPUBurstAboveThresholdSeconds(),
 		},
 		collectErrors:   metricFactory.NewAgentCollectErrorsTotal(),
 		collectDuration: metricFactory.NewAgentCollectDurationSeconds(),
 		loadCalculator:  calculator, // 注入负载计算器
+		burstSampler:    burstSampler,
+	}
+	// 只注册当前负载模式的指标：另一种模式的指标不会被更新，常量 0 会被看板误读为空闲
+	if cfg.LoadMode == LoadModeInstantRunQueue {
+		c.metrics.RunQueue1 = metricFactory.NewCPURunQueueAvg1()
+		c.metrics.RunQueue5 = metricFactory.NewCPURunQueueAvg5()
+		c.metrics.RunQueue15 = metricFactory.NewCPURunQueueAvg15()
+	} else {
+		c.metrics.Load1 = metricFactory.NewCPULoad1()
+		c.metrics.Load5 = metricFactory.NewCPULoad5()
+		c.metrics.Load15 = metricFactory.NewCPULoad15()
+		c.metrics.Load1PerCPU = metricFactory.NewCPULoad1PerCPU()
+		c.metrics.Load5PerCPU = metricFactory.NewCPULoad5PerCPU()
+		c.metrics.Load15PerCPU = metricFactory.NewCPULoad15PerCPU()
+		c.metrics.TasksRunning = metricFactory.NewCPULoadTasksRunning()
+		c.metrics.TasksTotal = metricFactory.NewCPULoadTasksTotal()
 	}
+	return c
 }
 
 // Name 返回采集器名称
@@ -104,10 +266,16 @@ func (c *CPUCollector) Init() error {
 			logger.Error("failed to start load calculator", zap.Error(err))
 			//	启动失败，标记为不可用
 			c.loadCalculator = nil
+		} else {
+			c.stopLoadSample = c.loadCalculator.StopLoad
 		}
-	} else {
+	} else if c.cfg.LoadMode == LoadModeInstantRunQueue {
 		logger.Warn("load calculator is not initialized, skip load collection")
 	}
+	// 3. 启动 CPU 突发检测采样器（可选）
+	if c.burstSampler != nil {
+		c.burstSampler.Start()
+	}
 	return nil
 }
 
@@ -121,43 +289,26 @@ func (c *CPUCollector) Collect(ctx context.Context) error {
 
 	logger.Debug("collect CPU info", zap.String("name", c.name))
 
-	// 1. 采集CPU使用率 整体/每核
-	usageList, err := cpu.Percent(0, c.cfg.Proc.CollectPerCore)
-	if err != nil {
+	// 1. 采集CPU时间、使用率（整体/每核）：/proc/stat 每个周期只从快照读取一次
+	if err := c.collectCPUFromProc(ctx); err != nil {
+		logger.Error("failed to collect CPU info", zap.Error(err))
 		c.collectErrors.WithLabelValues(c.name).Inc()
 		return fmt.Errorf("get cpu usage failed: %w", err)
 	}
-
-	// 2. 更新使用率指标
-	if c.cfg.Proc.CollectPerCore {
-		for i, usage := range usageList {
-			c.metrics.UsageRatio.WithLabelValues(fmt.Sprintf("cpu-%d", i)).Set(usage / 100)
-		}
-	} else {
-		c.metrics.UsageRatio.WithLabelValues("total").Set(usageList[0] / 100)
-	}
-
-	// 3. 采集CPU负载
-	if c.loadCalculator != nil {
-		load1, load5, load15, initialized := c.loadCalculator.GetLoads()
-		if initialized {
-			// 更新CPU负载指标
-			c.metrics.Load1.Set(load1)
-			c.metrics.Load5.Set(load5)
-			c.metrics.Load15.Set(load15)
-			logger.Debug("load calculator success", zap.Float64("load1", load1), zap.Float64("load5", load5), zap.Float64("load15", load15))
-		} else {
-			logger.Debug("load calculator is not initialized, skip load calculator")
-		}
-	} else {
-		logger.Warn("load collection is disabled (load calculator not available)")
+	// collectCPUFromProc 读取成功后才开始本轮序列跟踪，所有序列写入之后结束
+	defer c.endCycle()
+
+	// 2. 采集CPU负载
+	if c.cfg.LoadMode == LoadModeInstantRunQueue {
+		c.collectRunQueue()
+	} else if err := c.collectLoadAvg(ctx); err != nil {
+		logger.Error("failed to collect load average", zap.Error(err))
 		c.collectErrors.WithLabelValues(c.name).Inc()
 	}
 
-	// UsagePercent/UsageModePercent
-	if err = c.collectCPUFromProc(); err != nil {
-		logger.Error("failed to collect CPU info", zap.Error(err))
-		c.collectErrors.WithLabelValues(c.name).Inc()
+	// 突发检测：输出上一个采集窗口内的高频采样统计
+	if c.burstSampler != nil {
+		c.collectBurstStats()
 	}
 
 	// CPUInfo
@@ -168,192 +319,284 @@ func (c *CPUCollector) Collect(ctx context.Context) error {
 	return nil
 }
 
-// collectCPUFromProc 从/proc/stat读取CPU各模式时间（兼容ARM/x86）
-// 动态适配字段数量差异，缺失字段默认0.0，确保跨架构正常计算使用率
-func (c *CPUCollector) collectCPUFromProc() error {
-	open, err := os.Open("/proc/stat")
+// cpuDelta 计算两次采样之间各模式的时间增量及总增量
+// 计数器回退（iowait）时按 0 处理，避免出现负使用率；总增量为 0 时返回 false
+func cpuDelta(last, cur CPUTimes) (deltas [10]float64, deltaTotal float64, ok bool) {
+	curValues, lastValues := cur.values(), last.values()
+	for i := range curValues {
+		deltas[i] = math.Max(curValues[i]-lastValues[i], 0)
+		deltaTotal += deltas[i]
+	}
+	return deltas, deltaTotal, deltaTotal > 0
+}
+
+// busyPercent 非空闲时间占比（100% - 空闲率）
+func busyPercent(deltas [10]float64, deltaTotal float64) float64 {
+	return (deltaTotal - deltas[3]) / deltaTotal * 100
+}
+
+// collectCPUFromProc 从/proc/stat读取CPU各模式时间
+// 累计时间直接更新 cpu_seconds_total 计数器，同时与上一次采样做差计算使用率
+func (c *CPUCollector) collectCPUFromProc(ctx context.Context) error {
+	data, err := procfs.ReadFile(ctx, c.fs, procfs.ProcPath("stat"), &c.statBuf)
 	if err != nil {
-		return fmt.Errorf("open /proc/stat: %w", err)
-	}
-	defer open.Close()
-	scanner := bufio.NewScanner(open)
-	for scanner.Scan() {
-		line := scanner.Text()
-		fields := strings.Fields(line)
-		// 至少需要 "cpu" + 4个基础时间字段(user/nice/system/idle），否则跳过
-		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
-			continue
-		}
-		cpu_id := fields[0]
-		if cpu_id == "cpu" {
-			cpu_id = "total" // 总览行重命名为total，统一标签格式
-		}
+		return fmt.Errorf("read /proc/stat: %w", err)
+	}
+	// 读取失败的周期不计入：瞬时错误不能让所有 CPU 序列被当作下线删除、使用率基准被清空
+	c.series.Begin()
+	parseProcStat(data, func(raw []byte, times CPUTimes) {
+		label := c.labels.get(raw)
+		cpu_id := label.id
+		lastTime, exists := c.lastCPUTimes[cpu_id]
 
-		// 初始化CPU时间结构体，缺失字段默认0.0
-		times := CPUTimes{}
-		// 动态解析字段：按顺序读取，存在则解析，不存在则保持默认0.0
-		// 字段顺序（Linux标准）：user(1) → nice(2) → system(3) → idle(4) → iowait(5) → irq(6) → softirq(7) → steal(8)
-		if len(fields) >= 2 {
-			times.User, _ = strconv.ParseFloat(fields[1], 64)
-		}
-		if len(fields) >= 3 {
-			times.Nice, _ = strconv.ParseFloat(fields[2], 64)
-		}
-		if len(fields) >= 4 {
-			times.System, _ = strconv.ParseFloat(fields[3], 64)
-		}
-		if len(fields) >= 5 {
-			times.Idle, _ = strconv.ParseFloat(fields[4], 64)
-		}
-		if len(fields) >= 6 {
-			times.Iowait, _ = strconv.ParseFloat(fields[5], 64)
-		}
-		if len(fields) >= 7 {
-			times.Irq, _ = strconv.ParseFloat(fields[6], 64)
-		}
-		if len(fields) >= 8 {
-			times.Softirq, _ = strconv.ParseFloat(fields[7], 64)
-		}
-		if len(fields) >= 9 {
-			times.Steal, _ = strconv.ParseFloat(fields[8], 64)
+		// 更新累计计数器：首次采集直接加上内核累计值，之后只加增量
+		// 部分内核上单核 iowait 会回退，回退的增量直接忽略以保证计数器单调
+		current, last := times.values(), lastTime.values()
+		for i, mode := range cpuModes {
+			delta := current[i]
+			if exists {
+				delta = current[i] - last[i]
+			}
+			counter := c.metrics.SecondsTotal.WithLabelValues(cpu_id, mode) // 值为 0 的模式也输出序列
+			c.series.Touch(c.metrics.SecondsTotal, cpu_id, mode)
+			if delta > 0 {
+				counter.Add(delta)
+			}
 		}
 
-		//  计算总时间
-		total := times.User + times.Nice + times.System + times.Idle + times.Iowait + times.Irq + times.Softirq + times.Steal
-		//  如果有上一次的记录，计算使用率
-		lastTime, exists := c.lastCPUTimes[cpu_id]
 		if !exists {
 			// 首次采集：仅存储当前时间，不计算使用率（无历史数据对比）
 			c.lastCPUTimes[cpu_id] = times
 			logger.Debug("first collect CPU times (skip usage calc)", zap.String("cpu", cpu_id), zap.Any("times", times))
-			continue
+			return
 		}
-		// 计算各模式时间差（当前 - 上次）
-		deltaUser := times.User - lastTime.User
-		deltaNice := times.Nice - lastTime.Nice
-		deltaSystem := times.System - lastTime.System
-		deltaIdle := times.Idle - lastTime.Idle
-		deltaIowait := times.Iowait - lastTime.Iowait
-		deltaIrq := times.Irq - lastTime.Irq
-		deltaSoftirq := times.Softirq - lastTime.Softirq
-		deltaSteal := times.Steal - lastTime.Steal
-		deltaTotal := total - (lastTime.User + lastTime.Nice + lastTime.System + lastTime.Idle +
-			lastTime.Iowait + lastTime.Irq + lastTime.Softirq + lastTime.Steal)
-
-		// 避免除零（理论上deltaTotal不会为0，除非CPU完全未工作）
-		if deltaTotal <= 0 {
+		if !c.updateUsagePercent(label, lastTime, times) {
 			logger.Debug("CPU total time not changed (skip usage calc)", zap.String("cpu", cpu_id))
-			c.lastCPUTimes[cpu_id] = times // 更新最新时间，避免下次仍用旧数据
-			continue
 		}
+		// 保存当前时间，作为下次采集的历史基准（monotonic 回退的模式保留旧值）
+		c.lastCPUTimes[cpu_id] = maxCPUTimes(lastTime, times)
+	})
+	return nil
+}
+
+// updateUsagePercent 根据两次采样的时间差更新各模式使用率和总使用率
+// 返回 false 表示两次采样之间总时间没有变化，无法计算
+func (c *CPUCollector) updateUsagePercent(label cpuLabel, last, cur CPUTimes) bool {
+	cpu_id := label.id
+	deltas, deltaTotal, ok := cpuDelta(last, cur)
+	// 避免除零（理论上deltaTotal不会为0，除非CPU完全未工作）
+	if !ok {
+		return false
+	}
 
-		// 1. 更新各模式使用率指标（兼容缺失字段：缺失模式的delta为0，使用率显示0%）
-		modeMetrics := map[string]float64{
-			"user":    deltaUser,
-			"nice":    deltaNice,
-			"system":  deltaSystem,
-			"idle":    deltaIdle,
-			"iowait":  deltaIowait,
-			"irq":     deltaIrq,
-			"softirq": deltaSoftirq,
-			"steal":   deltaSteal,
+	// 1. 更新各模式使用率指标（兼容缺失字段：缺失模式的delta为0，使用率显示0%）
+	for i, mode := range cpuModes {
+		c.metrics.UsageModePercent.WithLabelValues(cpu_id, mode).Set(deltas[i] / deltaTotal * 100)
+		c.series.Touch(c.metrics.UsageModePercent, cpu_id, mode)
+	}
+	// 2. 更新总使用率指标（100% - 空闲率）
+	totalUsagePercent := busyPercent(deltas, deltaTotal)
+	c.metrics.UsagePercent.WithLabelValues(cpu_id).Set(totalUsagePercent)
+	c.series.Touch(c.metrics.UsagePercent, cpu_id)
+	// 3. cpu_usage_ratio：开启每核采集时输出各核，否则只输出整体
+	if perCore := cpu_id != "total"; perCore == c.cfg.CollectPerCore {
+		c.metrics.UsageRatio.WithLabelValues(label.ratio).Set(totalUsagePercent / 100)
+		c.series.Touch(c.metrics.UsageRatio, label.ratio)
+	}
+
+	// 调试日志：输出核心指标（仅保留关键信息，避免日志冗余）
+	logger.Debug("collected CPU mode usage",
+		zap.String("cpu", cpu_id),
+		zap.Float64("user", deltas[0]/deltaTotal*100),
+		zap.Float64("system", deltas[2]/deltaTotal*100),
+		zap.Float64("idle", deltas[3]/deltaTotal*100),
+		zap.Float64("total", totalUsagePercent))
+	return true
+}
+
+// collectLoadAvg kernel 模式：原样输出内核 load1/5/15、运行/总任务数，以及按在线 CPU 数归一化的负载
+func (c *CPUCollector) collectLoadAvg(ctx context.Context) error {
+	data, err := procfs.ReadFile(ctx, c.fs, procfs.ProcPath("loadavg"), &c.loadBuf)
+	if err != nil {
+		return fmt.Errorf("read /proc/loadavg: %w", err)
+	}
+	la, err := parseLoadAvg(data)
+	if err != nil {
+		return err
+	}
+	c.metrics.Load1.Set(la.Load1)
+	c.metrics.Load5.Set(la.Load5)
+	c.metrics.Load15.Set(la.Load15)
+	c.metrics.TasksRunning.Set(float64(la.Running))
+	c.metrics.TasksTotal.Set(float64(la.Total))
+
+	online := c.onlineCPUs(ctx)
+	c.metrics.Load1PerCPU.Set(la.Load1 / float64(online))
+	c.metrics.Load5PerCPU.Set(la.Load5 / float64(online))
+	c.metrics.Load15PerCPU.Set(la.Load15 / float64(online))
+	logger.Debug("collected kernel load average",
+		zap.Float64("load1", la.Load1), zap.Float64("load5", la.Load5), zap.Float64("load15", la.Load15),
+		zap.Uint64("running", la.Running), zap.Uint64("total", la.Total), zap.Int("online_cpus", online))
+	return nil
+}
+
+// onlineCPUs 在线 CPU 数（/sys/devices/system/cpu/online），读取失败时退回 Go 运行时可见的 CPU 数
+func (c *CPUCollector) onlineCPUs(ctx context.Context) int {
+	data, err := procfs.ReadFile(ctx, c.fs, sysCPUPath("online"), &c.onlineBuf)
+	if err == nil {
+		if n, ok := procfs.CountCPUList(data); ok {
+			return n
 		}
+	}
+	return runtime.NumCPU()
+}
 
-		for mode, delta := range modeMetrics {
-			usagePercent := delta / deltaTotal * 100
-			c.metrics.UsageModePercent.WithLabelValues(cpu_id, mode).Set(usagePercent)
+// collectRunQueue instant_run_queue 模式：输出基于 procs_running 的指数移动平均
+func (c *CPUCollector) collectRunQueue() {
+	if c.loadCalculator == nil {
+		logger.Warn("load collection is disabled (load calculator not available)")
+		c.collectErrors.WithLabelValues(c.name).Inc()
+		return
+	}
+	load1, load5, load15, initialized := c.loadCalculator.GetLoads()
+	if !initialized {
+		logger.Debug("load calculator is not initialized, skip load calculator")
+		return
+	}
+	c.metrics.RunQueue1.Set(load1)
+	c.metrics.RunQueue5.Set(load5)
+	c.metrics.RunQueue15.Set(load15)
+	logger.Debug("load calculator success", zap.Float64("run_queue1", load1), zap.Float64("run_queue5", load5), zap.Float64("run_queue15", load15))
+}
+
+// collectBurstStats 更新突发检测指标（max/p95/超阈值时间）
+func (c *CPUCollector) collectBurstStats() {
+	for cpu_id, stats := range c.burstSampler.Flush() {
+		c.metrics.BurstMax.WithLabelValues(cpu_id).Set(stats.Max)
+		c.metrics.BurstP95.WithLabelValues(cpu_id).Set(stats.P95)
+		c.metrics.BurstAbove.WithLabelValues(cpu_id).Set(stats.AboveThreshold.Seconds())
+		c.series.Touch(c.metrics.BurstMax, cpu_id)
+		c.series.Touch(c.metrics.BurstP95, cpu_id)
+		c.series.Touch(c.metrics.BurstAbove, cpu_id)
+	}
+}
+
+// endCycle 结束本轮序列跟踪：删除下线 CPU 的过期序列，并清理其历史时间
+// 清理与计数器序列同步进行，CPU 重新上线时计数器从内核累计值重新开始，不会重复累加
+func (c *CPUCollector) endCycle() {
+	for _, s := range c.series.End() {
+		if s.Vec == metrics.LabelDeleter(c.metrics.SecondsTotal) {
+			delete(c.lastCPUTimes, s.Labels[0])
 		}
-		// 2. 更新总使用率指标（100% - 空闲率）
-		totalUsagePercent := (deltaTotal - deltaIdle) / deltaTotal * 100
-		c.metrics.UsagePercent.WithLabelValues(cpu_id).Set(totalUsagePercent)
-
-		// 调试日志：输出核心指标（仅保留关键信息，避免日志冗余）
-		logger.Debug("collected CPU mode usage",
-			zap.String("cpu", cpu_id),
-			zap.Float64("user", modeMetrics["user"]/deltaTotal*100),
-			zap.Float64("system", modeMetrics["system"]/deltaTotal*100),
-			zap.Float64("idle", modeMetrics["idle"]/deltaTotal*100),
-			zap.Float64("total", totalUsagePercent))
-
-		// 保存当前时间，作为下次采集的历史基准
-		c.lastCPUTimes[cpu_id] = times
-	}
-	return scanner.Err()
+		logger.Debug("deleted stale CPU series", zap.Strings("labels", s.Labels))
+	}
+}
+
+// maxCPUTimes 逐模式取较大值，用于在计数器回退时保留历史基准
+func maxCPUTimes(a, b CPUTimes) CPUTimes {
+	return CPUTimes{
+		User:      math.Max(a.User, b.User),
+		Nice:      math.Max(a.Nice, b.Nice),
+		System:    math.Max(a.System, b.System),
+		Idle:      math.Max(a.Idle, b.Idle),
+		Iowait:    math.Max(a.Iowait, b.Iowait),
+		Irq:       math.Max(a.Irq, b.Irq),
+		Softirq:   math.Max(a.Softirq, b.Softirq),
+		Steal:     math.Max(a.Steal, b.Steal),
+		Guest:     math.Max(a.Guest, b.Guest),
+		GuestNice: math.Max(a.GuestNice, b.GuestNice),
+	}
 }
 
-// 更新 CPUInfo (读取 /proc/cpuinfo)
-// proc/cpuinfo 文件包含了每个 CPU 核心的详细信息
+// collectCPUInfoFromProc 更新 CPUInfo（读取 /proc/cpuinfo 和 sysfs 拓扑，仅执行一次）
+// 每个逻辑 CPU 输出一条 cpu_info 序列，携带 package/die/core/thread_siblings/node 标签，便于按 socket 分组和识别超线程
 func (c *CPUCollector) collectCPUInfoFromProc() error {
 	if c.cpuInfoInitialized {
 		return nil
 	}
-	open, err := os.Open("/proc/cpuinfo")
+	topologies, err := readCPUTopology(c.fs)
 	if err != nil {
-		return fmt.Errorf("open /proc/cpuinfo: %w", err)
+		return err
 	}
-	defer open.Close()
-	buf := bufio.NewScanner(open)
+	summary := summarizeCPUTopology(topologies)
+	cores := strconv.Itoa(summary.PhysicalCores)
 
-	for buf.Scan() {
-		line := buf.Text()
-		splitN := strings.SplitN(line, ":", 2)
-		if len(splitN) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(splitN[0])
-		value := strings.TrimSpace(splitN[1])
-		switch key {
-		case "model name":
-			c.modelName = value
-		case "cpu cores":
-			// x86架构: 直接读取物理核心数
-			c.physicalCores, _ = strconv.ParseInt(value, 10, 64)
-			c.hasCPUCores = true
-		case "cpu id":
-			//	ARM架构：采集core id, 用于去重统计物理核心数
-			c.coreID = value
-			c.coreIDSet[c.coreID] = struct{}{} // 去重统计
-		case "processor":
-			// 更新逻辑CPU序号，同时统计逻辑核心数（取最大序号+1）
-			c.cpuId = value
-			currentLogical, _ := strconv.ParseInt(value, 10, 64)
-			if currentLogical+1 > c.logicalCores {
-				c.logicalCores = currentLogical + 1
-			}
-		}
-	}
-	// 确定最准的核心数(优先级：cpu cores > core id 去重数 > 逻辑核心数)
-	if c.hasCPUCores {
-		c.finalCores = c.physicalCores
-	} else if len(c.coreIDSet) > 0 {
-		c.finalCores = int64(len(c.coreIDSet)) // ARM架构：物理核心数=core id去重后的数量
-	} else {
-		c.finalCores = c.logicalCores // 兜底：逻辑核心数
-	}
-	//	保存每个逻辑cPU的信息(如果是多核,会循环采集多个processor)
-	if c.cpuId != "" {
+	for _, t := range topologies {
 		c.metrics.CPUInfo.WithLabelValues(
-			c.cpuId, c.modelName, strconv.FormatInt(c.finalCores, 10), // 最终核心数转字符串
+			strconv.Itoa(t.CPU), t.ModelName, cores, t.Package, t.Die, t.Core, t.ThreadSiblings, t.Node,
 		).Set(1)
 
 		logger.Debug("collected CPU static info",
-			zap.String("cpu_id", c.cpuId),
-			zap.String("model_name", c.modelName),
-			zap.Int64("physical_cores", c.finalCores),
-			zap.Int64("logical_cores", c.logicalCores))
+			zap.Int("cpu_id", t.CPU),
+			zap.String("model_name", t.ModelName),
+			zap.String("package", t.Package),
+			zap.String("die", t.Die),
+			zap.String("core", t.Core),
+			zap.String("node", t.Node))
 	}
+	c.collectCPUIdentity(topologies)
+	if err := c.collectCPUVulnerabilities(); err != nil {
+		logger.Warn("failed to read CPU vulnerabilities", zap.Error(err))
+	}
+
+	c.metrics.Packages.Set(float64(summary.Packages))
+	c.metrics.PhysicalCores.Set(float64(summary.PhysicalCores))
+	c.metrics.LogicalCPUs.Set(float64(summary.LogicalCPUs))
+
 	c.cpuInfoInitialized = true
 	logger.Info("CPU static info collection completed",
-		zap.Int64("physical_cores", c.finalCores),
-		zap.Int64("logical_cores", c.logicalCores))
+		zap.Int("packages", summary.Packages),
+		zap.Int("physical_cores", summary.PhysicalCores),
+		zap.Int("logical_cores", summary.LogicalCPUs))
+	return nil
+}
 
-	return buf.Err()
+// collectCPUIdentity 输出 microcode/stepping 等型号标识（按 package 去重）和 CPU 特性 flags（各 CPU 取并集）
+func (c *CPUCollector) collectCPUIdentity(topologies []CPUTopology) {
+	flags := make(map[string]struct{})
+	for _, t := range topologies {
+		c.metrics.MicrocodeInfo.WithLabelValues(t.Package, t.Vendor, t.Family, t.Model, t.Stepping, t.Microcode).Set(1)
+		for _, f := range t.Flags {
+			flags[f] = struct{}{}
+		}
+	}
+	for f := range flags {
+		c.metrics.FlagInfo.WithLabelValues(f).Set(1)
+	}
+	if len(topologies) > 0 {
+		logger.Debug("collected CPU identity",
+			zap.String("vendor", topologies[0].Vendor),
+			zap.String("stepping", topologies[0].Stepping),
+			zap.String("microcode", topologies[0].Microcode),
+			zap.Int("flags", len(flags)))
+	}
+}
 
+// collectCPUVulnerabilities 每个漏洞输出一条 cpu_vulnerability_info，携带状态和缓解措施
+func (c *CPUCollector) collectCPUVulnerabilities() error {
+	vulns, err := readCPUVulnerabilities(c.fs)
+	if err != nil {
+		return err
+	}
+	vulnerable := 0
+	for _, v := range vulns {
+		c.metrics.VulnerabilityInfo.WithLabelValues(v.Name, v.Status, v.Mitigation).Set(1)
+		if v.Status == "vulnerable" {
+			vulnerable++
+			logger.Warn("CPU is vulnerable", zap.String("vulnerability", v.Name), zap.String("detail", v.Mitigation))
+		}
+	}
+	logger.Debug("collected CPU vulnerabilities", zap.Int("total", len(vulns)), zap.Int("vulnerable", vulnerable))
+	return nil
 }
+
 func (c *CPUCollector) Close() error {
 	if c.stopLoadSample != nil {
 		c.stopLoadSample()
 		logger.Info("load calculator stopped successfully")
 	}
+	if c.burstSampler != nil {
+		c.burstSampler.Stop()
+	}
 	return nil
 }
@@ -0,0 +1,50 @@
+package collector_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/collector"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TestCPULoadModeMetrics 只导出当前负载模式的指标，另一模式的指标不应以常量 0 出现
+func TestCPULoadModeMetrics(t *testing.T) {
+	kernel := []string{"cpu_load1", "cpu_load5", "cpu_load15", "cpu_load1_per_cpu", "cpu_load5_per_cpu", "cpu_load15_per_cpu",
+		"cpu_load_tasks_running", "cpu_load_tasks_total"}
+	runQueue := []string{"cpu_run_queue_avg1", "cpu_run_queue_avg5", "cpu_run_queue_avg15"}
+	cases := []struct {
+		mode       string
+		want, drop []string
+	}{
+		{collector.LoadModeKernel, kernel, runQueue},
+		{collector.LoadModeInstantRunQueue, runQueue, kernel},
+	}
+	for _, tc := range cases {
+		t.Run(tc.mode, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			cfg := &collector.ProcConfig{LoadMode: tc.mode, LoadSampleCycle: time.Second}
+			collector.NewCPUCollector(cfg, *metrics.NewMetricFactory(metrics.NewPromRegistry(reg)), procfs.MapFS{})
+			families, err := reg.Gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+			exported := make(map[string]bool, len(families))
+			for _, mf := range families {
+				exported[mf.GetName()] = true
+			}
+			for _, name := range tc.want {
+				if !exported[name] {
+					t.Errorf("%s not registered in %s mode", name, tc.mode)
+				}
+			}
+			for _, name := range tc.drop {
+				if exported[name] {
+					t.Errorf("%s registered in %s mode", name, tc.mode)
+				}
+			}
+		})
+	}
+}
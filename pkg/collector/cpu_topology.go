@@ -0,0 +1,230 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/agent-collector/pkg/procfs"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sysCPUPath 逻辑 CPU 拓扑信息所在的 sysfs 目录（/sys/devices/system/cpu）下的路径
+func sysCPUPath(elem ...string) string {
+	return procfs.SysPath(append([]string{"devices", "system", "cpu"}, elem...)...)
+}
+
+// CPUTopology 单个逻辑 CPU 的静态信息（型号来自 /proc/cpuinfo，拓扑优先来自 sysfs）
+type CPUTopology struct {
+	CPU            int    // 逻辑CPU序号（processor字段 / cpuN）
+	ModelName      string // 型号名称
+	Package        string // 物理封装（socket）ID：physical_package_id
+	Die            string // die ID（老内核或不支持时为 "0"）
+	Core           string // 物理核心ID：core_id
+	ThreadSiblings string // 同一物理核心上的逻辑CPU列表（如 "0,32"）
+	Node           string // NUMA 节点ID（无 NUMA 信息时为 "0"）
+
+	Vendor    string   // vendor_id（ARM 为 CPU implementer）
+	Family    string   // cpu family（ARM 为 CPU architecture）
+	Model     string   // model 编号（ARM 为 CPU part）
+	Stepping  string   // stepping（ARM 为 CPU variant）
+	Microcode string   // microcode 版本（ARM 无此字段，为空）
+	Flags     []string // flags（ARM 为 Features）
+}
+
+// CPUTopologySummary 拓扑汇总计数
+type CPUTopologySummary struct {
+	Packages      int // 物理封装数
+	PhysicalCores int // 物理核心数（package/die/core 去重）
+	LogicalCPUs   int // 逻辑CPU数
+}
+
+// cpuinfoBlock /proc/cpuinfo 中单个 processor 块的关键字段
+type cpuinfoBlock struct {
+	processor  int
+	modelName  string
+	physicalID string
+	coreID     string
+	vendor     string
+	family     string
+	model      string
+	stepping   string
+	microcode  string
+	flags      string
+}
+
+// readCPUTopology 读取所有在线逻辑 CPU 的型号和拓扑信息，按逻辑CPU序号排序
+// sysfs 拓扑不可用时（如部分容器/老内核）退回 /proc/cpuinfo 的 physical id/core id
+func readCPUTopology(fsys procfs.FS) ([]CPUTopology, error) {
+	blocks, err := parseCPUInfoBlocks(fsys, procfs.ProcPath("cpuinfo"))
+	if err != nil {
+		return nil, err
+	}
+
+	topologies := make([]CPUTopology, 0, len(blocks))
+	for _, b := range blocks {
+		t := CPUTopology{
+			CPU:       b.processor,
+			ModelName: b.modelName,
+			Package:   defaultString(b.physicalID, "0"),
+			Die:       "0",
+			Core:      defaultString(b.coreID, strconv.Itoa(b.processor)),
+			Node:      "0",
+			Vendor:    b.vendor,
+			Family:    b.family,
+			Model:     b.model,
+			Stepping:  b.stepping,
+			Microcode: b.microcode,
+			Flags:     strings.Fields(b.flags),
+		}
+		cpuDir := sysCPUPath(fmt.Sprintf("cpu%d", b.processor))
+		topoDir := filepath.Join(cpuDir, "topology")
+		if v, err := readTrimmed(fsys, filepath.Join(topoDir, "physical_package_id")); err == nil {
+			t.Package = v
+		}
+		if v, err := readTrimmed(fsys, filepath.Join(topoDir, "die_id")); err == nil {
+			t.Die = v
+		}
+		if v, err := readTrimmed(fsys, filepath.Join(topoDir, "core_id")); err == nil {
+			t.Core = v
+		}
+		if v, err := readTrimmed(fsys, filepath.Join(topoDir, "thread_siblings_list")); err == nil {
+			t.ThreadSiblings = v
+		} else {
+			t.ThreadSiblings = strconv.Itoa(b.processor)
+		}
+		// NUMA 节点以 cpuN/nodeX 符号链接的形式存在
+		if node, ok := cpuNode(fsys, cpuDir); ok {
+			t.Node = node
+		}
+		topologies = append(topologies, t)
+	}
+	sort.Slice(topologies, func(i, j int) bool { return topologies[i].CPU < topologies[j].CPU })
+	return topologies, nil
+}
+
+// summarizeCPUTopology 统计物理封装数、物理核心数和逻辑CPU数
+// 物理核心按 (package, die, core) 去重：core_id 只在同一个 package/die 内唯一
+func summarizeCPUTopology(topologies []CPUTopology) CPUTopologySummary {
+	packages := make(map[string]struct{})
+	cores := make(map[[3]string]struct{})
+	for _, t := range topologies {
+		packages[t.Package] = struct{}{}
+		cores[[3]string{t.Package, t.Die, t.Core}] = struct{}{}
+	}
+	return CPUTopologySummary{
+		Packages:      len(packages),
+		PhysicalCores: len(cores),
+		LogicalCPUs:   len(topologies),
+	}
+}
+
+// cpuNode 返回 cpuDir 下第一个 nodeX 条目的节点ID
+func cpuNode(fsys procfs.FS, cpuDir string) (string, bool) {
+	entries, err := fsys.ReadDir(cpuDir)
+	if err != nil {
+		return "", false
+	}
+	for _, e := range entries {
+		id := strings.TrimPrefix(e.Name(), "node")
+		if id == e.Name() || id == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(id); err == nil {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// parseCPUInfoBlocks 按空行分块解析 /proc/cpuinfo，每个 processor 块对应一个逻辑 CPU
+func parseCPUInfoBlocks(fsys procfs.FS, path string) ([]cpuinfoBlock, error) {
+	open, err := fsys.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer open.Close()
+
+	var blocks []cpuinfoBlock
+	var cur *cpuinfoBlock
+	scanner := bufio.NewScanner(open)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch key {
+		case "processor":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				// ARM 老内核的 "Processor : ARMv7 ..." 是型号而不是序号
+				continue
+			}
+			blocks = append(blocks, cpuinfoBlock{processor: n})
+			cur = &blocks[len(blocks)-1]
+		case "model name":
+			if cur != nil {
+				cur.modelName = value
+			}
+		case "physical id":
+			if cur != nil {
+				cur.physicalID = value
+			}
+		case "core id":
+			if cur != nil {
+				cur.coreID = value
+			}
+		default:
+			if cur != nil {
+				setCPUInfoIdent(cur, key, value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// setCPUInfoIdent 记录型号标识和特性字段，x86 与 ARM 字段名不同，统一到同一组字段
+func setCPUInfoIdent(b *cpuinfoBlock, key, value string) {
+	switch key {
+	case "vendor_id", "CPU implementer":
+		b.vendor = value
+	case "cpu family", "CPU architecture":
+		b.family = value
+	case "model", "CPU part":
+		b.model = value
+	case "stepping", "CPU variant":
+		b.stepping = value
+	case "microcode":
+		b.microcode = value
+	case "flags", "Features":
+		b.flags = value
+	}
+}
+
+// readTrimmed 读取 sysfs 单值文件并去掉首尾空白
+func readTrimmed(fsys procfs.FS, path string) (string, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func defaultString(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"errors"
+	"github.com/agent-collector/pkg/procfs"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CPUVulnerability /sys/devices/system/cpu/vulnerabilities 下单个漏洞的状态
+type CPUVulnerability struct {
+	Name       string // 文件名，如 spectre_v2、mds
+	Status     string // vulnerable / mitigation / not_affected / unknown
+	Mitigation string // 内核给出的完整描述（如 "Enhanced IBRS; IBPB: conditional"）
+}
+
+// readCPUVulnerabilities 读取内核报告的 CPU 漏洞状态（按名称排序）
+// 老内核（< 4.15）没有该目录，返回空列表
+func readCPUVulnerabilities(fsys procfs.FS) ([]CPUVulnerability, error) {
+	dir := sysCPUPath("vulnerabilities")
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var vulns []CPUVulnerability
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		text, err := readTrimmed(fsys, filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		status, mitigation := classifyVulnerability(text)
+		vulns = append(vulns, CPUVulnerability{Name: e.Name(), Status: status, Mitigation: mitigation})
+	}
+	sort.Slice(vulns, func(i, j int) bool { return vulns[i].Name < vulns[j].Name })
+	return vulns, nil
+}
+
+// classifyVulnerability 将内核描述归类为状态，并去掉状态前缀得到缓解措施描述
+// 例："Mitigation: PTI" -> (mitigation, "PTI")；"Vulnerable: Clear CPU buffers attempted, no microcode" -> (vulnerable, "Clear CPU buffers attempted, no microcode")
+// 虚拟机中可能出现 "KVM: Mitigation: ..." / "KVM: Vulnerable" 前缀，按其后的状态归类
+func classifyVulnerability(text string) (status, mitigation string) {
+	body := strings.TrimPrefix(text, "KVM: ")
+	switch {
+	case strings.HasPrefix(body, "Not affected"):
+		return "not_affected", ""
+	case strings.HasPrefix(body, "Mitigation"):
+		return "mitigation", trimStatusPrefix(body, "Mitigation")
+	case strings.HasPrefix(body, "Vulnerable"):
+		return "vulnerable", trimStatusPrefix(body, "Vulnerable")
+	default:
+		return "unknown", text
+	}
+}
+
+func trimStatusPrefix(text, prefix string) string {
+	rest := strings.TrimPrefix(text, prefix)
+	rest = strings.TrimPrefix(rest, ":")
+	return strings.TrimSpace(rest)
+}
@@ -0,0 +1,369 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/registers"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// execWaitDelay 超时杀进程后等待输出管道关闭的最长时间
+const execWaitDelay = 2 * time.Second
+
+// execResult 单条命令最近一次执行结果
+type execResult struct {
+	exitCode   int
+	duration   time.Duration
+	timedOut   bool
+	parseError bool
+	finished   time.Time
+	families   map[string]*dto.MetricFamily
+}
+
+// ExecConfig 脚本执行采集配置（collectors.exec，每条命令独立调度）
+type ExecConfig struct {
+	Commands []ExecCommandConfig `yaml:"commands" mapstructure:"commands" comment:"需要执行的命令列表"`
+}
+
+// ExecCommandConfig 单条命令配置
+type ExecCommandConfig struct {
+	Name     string        `yaml:"name" mapstructure:"name" comment:"命令名称（指标command标签，唯一）"`
+	Command  string        `yaml:"command" mapstructure:"command" comment:"可执行文件路径"`
+	Args     []string      `yaml:"args" mapstructure:"args" comment:"命令参数"`
+	Env      []string      `yaml:"env" mapstructure:"env" comment:"额外环境变量（KEY=VALUE）"`
+	WorkDir  string        `yaml:"work_dir" mapstructure:"work_dir" comment:"工作目录"`
+	Interval time.Duration `yaml:"interval" mapstructure:"interval" comment:"执行间隔（如60s）" default:"60s"`
+	Timeout  time.Duration `yaml:"timeout" mapstructure:"timeout" comment:"执行超时，超时后杀掉整个进程组（如10s）" default:"10s"`
+	Format   string        `yaml:"format" mapstructure:"format" comment:"输出格式（prometheus/simple）" default:"prometheus"`
+}
+
+// Validate 校验命令列表，并为未配置的间隔/超时/格式补默认值
+func (e *ExecConfig) Validate() error {
+	if len(e.Commands) == 0 {
+		return fmt.Errorf("exec.commands cannot be empty when exec collector is enabled")
+	}
+	seen := map[string]bool{}
+	for i := range e.Commands {
+		cmd := &e.Commands[i]
+		if strings.TrimSpace(cmd.Name) == "" {
+			return fmt.Errorf("exec.commands[%d].name cannot be empty", i)
+		}
+		if seen[cmd.Name] {
+			return fmt.Errorf("exec.commands duplicated name: %q", cmd.Name)
+		}
+		seen[cmd.Name] = true
+		if strings.TrimSpace(cmd.Command) == "" {
+			return fmt.Errorf("exec.commands[%s].command cannot be empty", cmd.Name)
+		}
+		if cmd.Interval == 0 {
+			cmd.Interval = 60 * time.Second
+		}
+		if cmd.Timeout == 0 {
+			cmd.Timeout = 10 * time.Second
+		}
+		if cmd.Interval < time.Second {
+			return fmt.Errorf("exec.commands[%s].interval must be at least 1s, got %s", cmd.Name, cmd.Interval)
+		}
+		if cmd.Timeout < 0 || cmd.Timeout > cmd.Interval {
+			return fmt.Errorf("exec.commands[%s].timeout must be positive and not exceed interval, got %s", cmd.Name, cmd.Timeout)
+		}
+		if cmd.Format == "" {
+			cmd.Format = "prometheus"
+		}
+		if cmd.Format != "prometheus" && cmd.Format != "simple" {
+			return fmt.Errorf("exec.commands[%s].format must be 'prometheus' or 'simple', got %s", cmd.Name, cmd.Format)
+		}
+		for _, kv := range cmd.Env {
+			if !strings.Contains(kv, "=") {
+				return fmt.Errorf("exec.commands[%s].env entry %q must be KEY=VALUE", cmd.Name, kv)
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	registers.RegisterFactory(registers.Factory{
+		Name:        "exec",
+		Description: "scripts and commands, configured in the config file (脚本/命令执行，命令在配置文件中配置)",
+		NewConfig:   func() any { return &ExecConfig{} },
+		New: func(cfg any, deps registers.Deps) (registers.Collector, error) {
+			return NewExecCollector(cfg.(*ExecConfig), deps.MetricFactory), nil
+		},
+	})
+}
+
+// ExecCollector 脚本执行采集器（实现Collector接口）
+// 每条命令在独立的协程中按各自间隔执行，Collect 只负责发布最近一次结果
+type ExecCollector struct {
+	name            string
+	cfg             *ExecConfig
+	metrics         metrics.ExecCollectorMetrics
+	collectErrors   *prometheus.CounterVec
+	collectDuration *prometheus.HistogramVec
+
+	mu      sync.Mutex
+	results map[string]*execResult // 命令名称 -> 最近一次执行结果
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewExecCollector 创建脚本执行采集器
+func NewExecCollector(cfg *ExecConfig, metricFactory metrics.MetricFactory) *ExecCollector {
+	return &ExecCollector{
+		name: "exec-collector",
+		cfg:  cfg,
+		metrics: metrics.ExecCollectorMetrics{
+			ExitCode:    metricFactory.NewExecExitCode(),
+			Duration:    metricFactory.NewExecDurationSeconds(),
+			Timeout:     metricFactory.NewExecTimeout(),
+			ParseError:  metricFactory.NewExecParseError(),
+			LastRunTime: metricFactory.NewExecLastRunTimestamp(),
+			Families:    metricFactory.NewFamilyCollector(),
+		},
+		collectErrors:   metricFactory.NewAgentCollectErrorsTotal(),
+		collectDuration: metricFactory.NewAgentCollectDurationSeconds(),
+		results:         make(map[string]*execResult),
+	}
+}
+
+// Name 返回采集器名称
+func (c *ExecCollector) Name() string { return c.name }
+
+// Init 检查可执行文件并为每条命令启动调度协程
+func (c *ExecCollector) Init() error {
+	for _, cmd := range c.cfg.Commands {
+		if _, err := exec.LookPath(cmd.Command); err != nil {
+			logger.Error("exec command not found", zap.String("command", cmd.Name), zap.String("path", cmd.Command), zap.Error(err))
+			return fmt.Errorf("exec command %s: %w", cmd.Name, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	for _, cmd := range c.cfg.Commands {
+		c.wg.Add(1)
+		go c.schedule(ctx, cmd)
+	}
+	return nil
+}
+
+// schedule 按命令自身的间隔循环执行（启动后立即执行一次）
+func (c *ExecCollector) schedule(ctx context.Context, cmd ExecCommandConfig) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(cmd.Interval)
+	defer ticker.Stop()
+
+	for {
+		result := runExecCommand(ctx, cmd)
+		c.mu.Lock()
+		c.results[cmd.Name] = result
+		c.mu.Unlock()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Collect 发布所有命令最近一次的执行结果和输出指标
+func (c *ExecCollector) Collect(ctx context.Context) error {
+	start := time.Now()
+	defer func() {
+		c.collectDuration.WithLabelValues(c.name).Observe(time.Since(start).Seconds())
+	}()
+
+	c.mu.Lock()
+	names := make([]string, 0, len(c.results))
+	for name := range c.results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	results := make([]*execResult, 0, len(names))
+	for _, name := range names {
+		results = append(results, c.results[name])
+	}
+	c.mu.Unlock()
+
+	merged := newFamilyMerger()
+	for i, r := range results {
+		name := names[i]
+		c.metrics.ExitCode.WithLabelValues(name).Set(float64(r.exitCode))
+		c.metrics.Duration.WithLabelValues(name).Set(r.duration.Seconds())
+		c.metrics.Timeout.WithLabelValues(name).Set(boolToFloat(r.timedOut))
+		c.metrics.LastRunTime.WithLabelValues(name).Set(float64(r.finished.UnixNano()) / 1e9)
+
+		parseError := r.parseError
+		if r.families != nil {
+			if err := merged.add(r.families); err != nil {
+				logger.Warn("exec command output conflicts with other commands", zap.String("command", name), zap.Error(err))
+				parseError = true
+			}
+		}
+		c.metrics.ParseError.WithLabelValues(name).Set(boolToFloat(parseError))
+	}
+	c.metrics.Families.Update(merged.families())
+	return nil
+}
+
+// Close 停止所有调度协程，正在执行的命令会被取消
+func (c *ExecCollector) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+		c.wg.Wait()
+	}
+	return nil
+}
+
+// runExecCommand 执行一次命令：超时后杀掉整个进程组，退出码为 0 时解析标准输出
+func runExecCommand(parent context.Context, cfg ExecCommandConfig) *execResult {
+	ctx, cancel := context.WithTimeout(parent, cfg.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, cfg.Command, cfg.Args...)
+	cmd.Dir = cfg.WorkDir
+	cmd.Env = append(os.Environ(), cfg.Env...)
+	cmd.WaitDelay = execWaitDelay
+	setProcessGroup(cmd)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	result := &execResult{
+		exitCode: 0,
+		duration: time.Since(start),
+		timedOut: errors.Is(ctx.Err(), context.DeadlineExceeded),
+		finished: time.Now(),
+	}
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.exitCode = exitErr.ExitCode() // 被信号杀死时为 -1
+		} else {
+			result.exitCode = -1
+		}
+		logger.Warn("exec command failed",
+			zap.String("command", cfg.Name),
+			zap.Int("exit_code", result.exitCode),
+			zap.Bool("timeout", result.timedOut),
+			zap.Duration("duration", result.duration),
+			zap.String("stderr", truncate(stderr.String(), 512)),
+			zap.Error(err))
+		return result
+	}
+
+	families, err := parseExecOutput(cfg.Format, stdout.Bytes())
+	if err != nil {
+		logger.Warn("failed to parse exec command output", zap.String("command", cfg.Name), zap.String("format", cfg.Format), zap.Error(err))
+		result.parseError = true
+		return result
+	}
+	result.families = families
+
+	logger.Debug("exec command finished", zap.String("command", cfg.Name), zap.Duration("duration", result.duration), zap.Int("families", len(families)))
+	return result
+}
+
+// parseExecOutput 按配置的格式解析命令输出
+func parseExecOutput(format string, out []byte) (map[string]*dto.MetricFamily, error) {
+	if format == "simple" {
+		return ParseSimpleMetrics(out)
+	}
+	return ParsePromText(bytes.NewReader(out))
+}
+
+// ParseSimpleMetrics 解析简单格式：每行 `name value [label=value ...]`，# 开头为注释
+// 所有指标按 gauge 输出
+func ParseSimpleMetrics(out []byte) (map[string]*dto.MetricFamily, error) {
+	families := make(map[string]*dto.MetricFamily)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected 'name value [label=value ...]', got %q", lineNo, line)
+		}
+		name := fields[0]
+		if !validMetricName(name) {
+			return nil, fmt.Errorf("line %d: invalid metric name %q", lineNo, name)
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid value %q: %w", lineNo, fields[1], err)
+		}
+
+		metric := &dto.Metric{Gauge: &dto.Gauge{Value: &value}}
+		for _, kv := range fields[2:] {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok || !validMetricName(k) {
+				return nil, fmt.Errorf("line %d: invalid label %q", lineNo, kv)
+			}
+			metric.Label = append(metric.Label, &dto.LabelPair{Name: &k, Value: &v})
+		}
+
+		mf, ok := families[name]
+		if !ok {
+			metricName, metricType := name, dto.MetricType_GAUGE
+			mf = &dto.MetricFamily{Name: &metricName, Type: &metricType}
+			families[name] = mf
+		}
+		mf.Metric = append(mf.Metric, metric)
+	}
+	return families, scanner.Err()
+}
+
+// validMetricName 校验指标/标签名：[a-zA-Z_:][a-zA-Z0-9_:]*
+func validMetricName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || r == ':' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// boolToFloat true -> 1, false -> 0
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// truncate 截断过长的字符串，避免日志被脚本输出淹没
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "...(truncated)"
+}
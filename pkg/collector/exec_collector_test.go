@@ -0,0 +1,242 @@
+//go:build unix
+
+package collector_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/collector"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// writeScript 在 dir 中写一个可执行的 sh 脚本
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// startExecCollector 校验配置、启动采集器，并等待每条命令至少执行完一次
+func startExecCollector(t *testing.T, commands []collector.ExecCommandConfig) (*collector.ExecCollector, *prometheus.Registry) {
+	t.Helper()
+	cfg := &collector.ExecConfig{Commands: commands}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("validate exec config: %v", err)
+	}
+	reg := prometheus.NewRegistry()
+	c := collector.NewExecCollector(cfg, *metrics.NewMetricFactory(metrics.NewPromRegistry(reg)))
+	if err := c.Init(); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	waitFor(t, "every command to finish once", func() bool {
+		if err := c.Collect(context.Background()); err != nil {
+			t.Fatalf("collect: %v", err)
+		}
+		for _, cmd := range commands {
+			if _, ok := gaugeValue(t, reg, "exec_command_last_run_timestamp_seconds", map[string]string{"command": cmd.Name}); !ok {
+				return false
+			}
+		}
+		return true
+	})
+	return c, reg
+}
+
+// processGone 进程不存在或已成为僵尸进程（容器内 init 可能不回收孤儿）
+func processGone(pid int) bool {
+	if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) {
+		return true
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	// 格式：pid (comm) state ...
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && (fields[0] == "Z" || fields[0] == "X")
+}
+
+func TestExecTimeoutKillsProcessGroup(t *testing.T) {
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "child.pid")
+	// 后台子进程继承 stdout：只杀 sh 本身时管道不会关闭，执行会拖到 WaitDelay
+	script := writeScript(t, dir, "hang.sh", `sleep 30 &
+echo $! > "$1"
+wait
+`)
+	_, reg := startExecCollector(t, []collector.ExecCommandConfig{
+		{Name: "hang", Command: script, Args: []string{pidFile}, Interval: time.Minute, Timeout: 200 * time.Millisecond},
+	})
+
+	labels := map[string]string{"command": "hang"}
+	if got, _ := gaugeValue(t, reg, "exec_command_timeout", labels); got != 1 {
+		t.Errorf("exec_command_timeout = %v, want 1", got)
+	}
+	if got, _ := gaugeValue(t, reg, "exec_command_exit_code", labels); got != -1 {
+		t.Errorf("exec_command_exit_code = %v, want -1 (killed by signal)", got)
+	}
+	if got, _ := gaugeValue(t, reg, "exec_command_duration_seconds", labels); got < 0.2 || got > 1.5 {
+		t.Errorf("exec_command_duration_seconds = %v, want just over the 200ms timeout", got)
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("read child pid: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("parse child pid %q: %v", data, err)
+	}
+	t.Cleanup(func() { _ = syscall.Kill(pid, syscall.SIGKILL) })
+	waitFor(t, "background child to be killed with the process group", func() bool { return processGone(pid) })
+}
+
+func TestExecExitCodeAndOutput(t *testing.T) {
+	dir := t.TempDir()
+	failing := writeScript(t, dir, "fail.sh", `echo "partial_result 1"
+echo "disk not mounted" >&2
+exit 3
+`)
+	simple := writeScript(t, dir, "simple.sh", `cat <<'OUT'
+# backup status
+backup_ok 1 job=db
+backup_ok 0 job=files
+OUT
+`)
+	garbage := writeScript(t, dir, "garbage.sh", "echo 'not { prometheus'\n")
+
+	_, reg := startExecCollector(t, []collector.ExecCommandConfig{
+		{Name: "fail", Command: failing, Interval: time.Minute, Timeout: 5 * time.Second},
+		{Name: "simple", Command: simple, Interval: time.Minute, Timeout: 5 * time.Second, Format: "simple"},
+		{Name: "garbage", Command: garbage, Interval: time.Minute, Timeout: 5 * time.Second},
+	})
+
+	cases := []struct {
+		command    string
+		exitCode   float64
+		parseError float64
+	}{
+		{"fail", 3, 0},
+		{"simple", 0, 0},
+		{"garbage", 0, 1},
+	}
+	for _, tc := range cases {
+		labels := map[string]string{"command": tc.command}
+		if got, _ := gaugeValue(t, reg, "exec_command_exit_code", labels); got != tc.exitCode {
+			t.Errorf("%s: exec_command_exit_code = %v, want %v", tc.command, got, tc.exitCode)
+		}
+		if got, _ := gaugeValue(t, reg, "exec_command_timeout", labels); got != 0 {
+			t.Errorf("%s: exec_command_timeout = %v, want 0", tc.command, got)
+		}
+		if got, ok := gaugeValue(t, reg, "exec_command_duration_seconds", labels); !ok || got <= 0 || got > 5 {
+			t.Errorf("%s: exec_command_duration_seconds = %v (found %v), want a positive duration", tc.command, got, ok)
+		}
+		if got, _ := gaugeValue(t, reg, "exec_command_parse_error", labels); got != tc.parseError {
+			t.Errorf("%s: exec_command_parse_error = %v, want %v", tc.command, got, tc.parseError)
+		}
+	}
+
+	// 非 0 退出时输出不采信
+	if _, ok := gaugeValue(t, reg, "partial_result", nil); ok {
+		t.Error("output of a failed command was exported")
+	}
+	for job, want := range map[string]float64{"db": 1, "files": 0} {
+		if got, ok := gaugeValue(t, reg, "backup_ok", map[string]string{"job": job}); !ok || got != want {
+			t.Errorf("backup_ok{job=%q} = %v (found %v), want %v", job, got, ok, want)
+		}
+	}
+}
+
+func TestParseSimpleMetrics(t *testing.T) {
+	type series struct {
+		labels map[string]string
+		value  float64
+	}
+	cases := []struct {
+		name    string
+		input   string
+		want    map[string][]series
+		wantErr string
+	}{
+		{
+			name:  "name and value",
+			input: "queue_depth 42\n",
+			want:  map[string][]series{"queue_depth": {{nil, 42}}},
+		},
+		{
+			name: "labels comments and blank lines",
+			input: `# written by backup.sh
+
+backup_ok 1 job=db host=db-1
+  backup_ok 0 job=files
+backup_size_bytes 1.5e9 job=db
+`,
+			want: map[string][]series{
+				"backup_ok":         {{map[string]string{"job": "db", "host": "db-1"}, 1}, {map[string]string{"job": "files"}, 0}},
+				"backup_size_bytes": {{map[string]string{"job": "db"}, 1.5e9}},
+			},
+		},
+		{
+			name:  "special values and colon names",
+			input: "job:latency:p99 NaN\ntemperature -Inf\n",
+			want:  map[string][]series{"job:latency:p99": {{nil, 0}}, "temperature": {{nil, 0}}},
+		},
+		{name: "empty output", input: "", want: map[string][]series{}},
+		{name: "missing value", input: "queue_depth\n", wantErr: "line 1: expected"},
+		{name: "bad value", input: "ok 1\nqueue_depth many\n", wantErr: `line 2: invalid value "many"`},
+		{name: "name starts with digit", input: "1st_metric 1\n", wantErr: "invalid metric name"},
+		{name: "name with dash", input: "queue-depth 1\n", wantErr: "invalid metric name"},
+		{name: "label without value", input: "queue_depth 1 job\n", wantErr: `invalid label "job"`},
+		{name: "bad label name", input: "queue_depth 1 job-name=x\n", wantErr: "invalid label"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			families, err := collector.ParseSimpleMetrics([]byte(tc.input))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if len(families) != len(tc.want) {
+				t.Fatalf("got %d families, want %d", len(families), len(tc.want))
+			}
+			for name, want := range tc.want {
+				mf := families[name]
+				if mf.GetType() != dto.MetricType_GAUGE {
+					t.Errorf("%s: type %v, want gauge", name, mf.GetType())
+				}
+				if len(mf.GetMetric()) != len(want) {
+					t.Fatalf("%s: %d series, want %d", name, len(mf.GetMetric()), len(want))
+				}
+				for i, w := range want {
+					m := mf.GetMetric()[i]
+					if v := m.GetGauge().GetValue(); w.value != 0 && v != w.value {
+						t.Errorf("%s[%d] = %v, want %v", name, i, v, w.value)
+					}
+					if !labelsMatch(m, w.labels) || len(m.GetLabel()) != len(w.labels) {
+						t.Errorf("%s[%d] labels = %v, want %v", name, i, m.GetLabel(), w.labels)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package collector
+
+import "os/exec"
+
+// setProcessGroup 非 unix 平台不支持进程组，超时时只杀掉命令本身
+func setProcessGroup(cmd *exec.Cmd) {}
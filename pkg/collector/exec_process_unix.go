@@ -0,0 +1,17 @@
+//go:build unix
+
+package collector
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup 让命令运行在独立进程组中，超时时连同其子进程一起杀掉
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		// 负 pid 表示向整个进程组发送信号
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
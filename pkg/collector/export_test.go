@@ -0,0 +1,6 @@
+package collector
+
+import "time"
+
+// SampleAt 在指定时刻执行一次突发采样，测试用来注入确定的采样时间
+func (s *CPUBurstSampler) SampleAt(now time.Time) error { return s.sample(now) }
@@ -0,0 +1,244 @@
+package collector_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/agent-collector/pkg/collector"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// 采集器行为变更后执行 go test ./pkg/collector -run TestFixtureGolden -update 重新生成 golden 文件，并在评审中检查 diff
+var update = flag.Bool("update", false, "update golden files under testdata/golden")
+
+// volatileMetrics 与运行环境相关、不参与 golden 对比的指标
+var volatileMetrics = map[string]bool{
+	"agent_collect_duration_seconds": true,
+}
+
+// TestFixtureGolden 使用 testdata/fixtures 下采集自 x86 / ARM 主机的 /proc、/sys 文件运行采集器，对比输出的 exposition 文本
+// fixture 由 testdata/fixtures/capture.sh 在对应主机上生成
+func TestFixtureGolden(t *testing.T) {
+	for _, host := range []string{"x86", "arm"} {
+		t.Run(host, func(t *testing.T) {
+			fsys := procfs.DirFS{Root: filepath.Join("testdata", "fixtures", host)}
+			reg := prometheus.NewRegistry()
+			factory := *metrics.NewMetricFactory(metrics.NewPromRegistry(reg))
+
+			collectors := []interface {
+				Collect(ctx context.Context) error
+			}{
+				collector.NewCPUCollector(&collector.ProcConfig{CollectPerCore: true, LoadMode: collector.LoadModeKernel}, factory, fsys),
+			}
+			// 与生产环境一致：内核未开启 CONFIG_SCHEDSTATS（fixture 中没有 /proc/schedstat）时不启用该采集器
+			if schedstat := collector.NewSchedstatCollector(&collector.SchedstatConfig{}, factory, fsys); schedstat.Init() == nil {
+				collectors = append(collectors, schedstat)
+			}
+			snapshot := procfs.NewSnapshot(fsys)
+			defer snapshot.Release()
+			ctx := procfs.WithSnapshot(context.Background(), snapshot)
+			for _, c := range collectors {
+				if err := c.Collect(ctx); err != nil {
+					t.Fatalf("collect: %v", err)
+				}
+			}
+
+			got := exposition(t, reg)
+			golden := filepath.Join("testdata", "golden", host+".prom")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("exposition differs from %s (run with -update to accept):\n%s", golden, lineDiff(string(want), string(got)))
+			}
+		})
+	}
+}
+
+// TestCPUCollectorMapFS 用内存文件系统模拟两个采集周期，验证使用率由 /proc/stat 增量计算
+func TestCPUCollectorMapFS(t *testing.T) {
+	fsys := procfs.MapFS{
+		"/proc/loadavg":                  {Data: []byte("2.00 1.00 0.50 2/100 42\n")},
+		"/sys/devices/system/cpu/online": {Data: []byte("0-1\n")},
+		"/proc/cpuinfo":                  {Data: []byte("processor\t: 0\nmodel name\t: test\n\nprocessor\t: 1\nmodel name\t: test\n")},
+	}
+	setStat := func(user0, idle0, user1, idle1 int) {
+		fsys["/proc/stat"] = &fstest.MapFile{Data: []byte(strings.Join([]string{
+			"cpu  " + strconv.Itoa(user0+user1) + " 0 0 " + strconv.Itoa(idle0+idle1) + " 0 0 0 0 0 0",
+			"cpu0 " + strconv.Itoa(user0) + " 0 0 " + strconv.Itoa(idle0) + " 0 0 0 0 0 0",
+			"cpu1 " + strconv.Itoa(user1) + " 0 0 " + strconv.Itoa(idle1) + " 0 0 0 0 0 0",
+			"procs_running 2",
+		}, "\n"))}
+	}
+
+	reg := prometheus.NewRegistry()
+	cfg := &collector.ProcConfig{CollectPerCore: true, LoadMode: collector.LoadModeKernel}
+	c := collector.NewCPUCollector(cfg, *metrics.NewMetricFactory(metrics.NewPromRegistry(reg)), fsys)
+
+	setStat(100, 100, 100, 100)
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	// cpu0 忙 75%，cpu1 空闲
+	setStat(175, 125, 100, 200)
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	for labels, want := range map[string]float64{"cpu-0": 0.75, "cpu-1": 0} {
+		if v, ok := gaugeValue(t, reg, "cpu_usage_ratio", map[string]string{"core": labels}); !ok || v != want {
+			t.Errorf("cpu_usage_ratio{core=%q} = %v (found %v), want %v", labels, v, ok, want)
+		}
+	}
+	if v, ok := gaugeValue(t, reg, "cpu_load1_per_cpu", nil); !ok || v != 1 {
+		t.Errorf("cpu_load1_per_cpu = %v (found %v), want 1", v, ok)
+	}
+	if v, ok := gaugeValue(t, reg, "cpu_logical_cpus", nil); !ok || v != 2 {
+		t.Errorf("cpu_logical_cpus = %v (found %v), want 2", v, ok)
+	}
+}
+
+// TestCPUCollectorReadFailureKeepsSeries /proc/stat 读取失败的周期不能把所有 CPU 当作下线，删除序列和使用率基准
+func TestCPUCollectorReadFailureKeepsSeries(t *testing.T) {
+	fsys := procfs.MapFS{
+		"/proc/loadavg":                  {Data: []byte("0.50 0.40 0.30 1/100 42\n")},
+		"/sys/devices/system/cpu/online": {Data: []byte("0\n")},
+		"/proc/cpuinfo":                  {Data: []byte("processor\t: 0\nmodel name\t: test\n")},
+	}
+	setStat := func(user, idle int) {
+		fsys["/proc/stat"] = &fstest.MapFile{Data: []byte(
+			"cpu  " + strconv.Itoa(user) + " 0 0 " + strconv.Itoa(idle) + " 0 0 0 0 0 0\n" +
+				"cpu0 " + strconv.Itoa(user) + " 0 0 " + strconv.Itoa(idle) + " 0 0 0 0 0 0\n")}
+	}
+	reg := prometheus.NewRegistry()
+	factory := metrics.NewMetricFactory(metrics.NewPromRegistry(reg))
+	factory.SeriesTracker().SetStaleCycles(1) // 一个周期未出现即视为下线，便于暴露误删
+	c := collector.NewCPUCollector(&collector.ProcConfig{CollectPerCore: true, LoadMode: collector.LoadModeKernel}, *factory, fsys)
+
+	setStat(100, 100)
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	setStat(150, 150)
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	delete(fsys, "/proc/stat")
+	for i := 0; i < 3; i++ {
+		if err := c.Collect(context.Background()); err == nil {
+			t.Fatal("collect succeeded without /proc/stat")
+		}
+	}
+	user := map[string]string{"cpu": "cpu0", "mode": "user"}
+	if v, ok := gaugeValue(t, reg, "cpu_seconds_total", user); !ok || v != 1.5 {
+		t.Errorf("cpu_seconds_total%v = %v (found %v) after failed reads, want 1.5 kept", user, v, ok)
+	}
+	if _, ok := gaugeValue(t, reg, "cpu_usage_ratio", map[string]string{"core": "cpu-0"}); !ok {
+		t.Error("cpu_usage_ratio deleted after failed reads")
+	}
+
+	// 恢复后使用率基于失败前的采样计算：cpu0 在 100 个 tick 中忙 75 个
+	setStat(225, 175)
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if v, ok := gaugeValue(t, reg, "cpu_usage_ratio", map[string]string{"core": "cpu-0"}); !ok || !approxEqual(v, 0.75) {
+		t.Errorf("cpu_usage_ratio after recovery = %v (found %v), want 0.75 from the kept baseline", v, ok)
+	}
+	if v, _ := gaugeValue(t, reg, "cpu_seconds_total", user); !approxEqual(v, 2.25) {
+		t.Errorf("cpu_seconds_total%v after recovery = %v, want 2.25", user, v)
+	}
+}
+
+// TestLoadCalculatorStop StopLoad 之后采样协程退出，负载不再更新
+func TestLoadCalculatorStop(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("load calculator only runs on Linux")
+	}
+	fsys := procfs.MapFS{"/proc/stat": {Data: []byte("procs_running 3\n")}}
+	calc, err := collector.NewLoadCalculator(5*time.Millisecond, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := calc.StartLoad(); err != nil {
+		t.Fatalf("StartLoad: %v", err)
+	}
+	waitFor(t, "first load sample", func() bool {
+		_, _, _, ok := calc.GetLoads()
+		return ok
+	})
+	calc.StopLoad()
+
+	load1, _, _, _ := calc.GetLoads()
+	fsys["/proc/stat"] = &fstest.MapFile{Data: []byte("procs_running 90\n")}
+	time.Sleep(30 * time.Millisecond)
+	if got, _, _, _ := calc.GetLoads(); got != load1 {
+		t.Errorf("load1 changed from %v to %v after StopLoad", load1, got)
+	}
+	calc.StopLoad() // 重复调用是安全的
+}
+
+// exposition 以文本格式输出 registry 中的指标（排除 volatileMetrics）
+func exposition(t *testing.T, reg *prometheus.Registry) []byte {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	var buf bytes.Buffer
+	for _, mf := range families {
+		if volatileMetrics[mf.GetName()] {
+			continue
+		}
+		if _, err := expfmt.MetricFamilyToText(&buf, mf); err != nil {
+			t.Fatalf("encode %s: %v", mf.GetName(), err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// lineDiff 列出两段文本中只出现在一侧的行
+func lineDiff(want, got string) string {
+	wantLines := make(map[string]bool)
+	for _, l := range strings.Split(want, "\n") {
+		wantLines[l] = true
+	}
+	gotLines := make(map[string]bool)
+	for _, l := range strings.Split(got, "\n") {
+		gotLines[l] = true
+	}
+	var b strings.Builder
+	for _, l := range strings.Split(want, "\n") {
+		if !gotLines[l] {
+			b.WriteString("- " + l + "\n")
+		}
+	}
+	for _, l := range strings.Split(got, "\n") {
+		if !wantLines[l] {
+			b.WriteString("+ " + l + "\n")
+		}
+	}
+	return b.String()
+}
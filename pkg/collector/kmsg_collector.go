@@ -0,0 +1,233 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/registers"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// kmsgPollInterval 读取普通文件到达末尾后的重试间隔（/dev/kmsg 和 fifo 会阻塞等待，不需要轮询）
+const kmsgPollInterval = 200 * time.Millisecond
+
+// KmsgConfig 内核日志事件采集配置（collectors.kmsg）
+type KmsgConfig struct {
+	Path string `yaml:"path" mapstructure:"path" comment:"内核日志路径（测试时可使用普通文件或fifo）"`
+}
+
+// Validate 内核日志路径不能为空
+func (k *KmsgConfig) Validate() error {
+	if strings.TrimSpace(k.Path) == "" {
+		return fmt.Errorf("kmsg.path cannot be empty when kmsg collector is enabled")
+	}
+	return nil
+}
+
+func init() {
+	registers.RegisterFactory(registers.Factory{
+		Name:        "kmsg",
+		Description: "kernel log events: OOM kill, hung task, I/O errors (内核日志事件：OOM/hung task/IO错误等)",
+		NewConfig:   func() any { return &KmsgConfig{Path: "/dev/kmsg"} },
+		New: func(cfg any, deps registers.Deps) (registers.Collector, error) {
+			return NewKmsgCollector(cfg.(*KmsgConfig), deps.MetricFactory), nil
+		},
+	})
+}
+
+// KmsgCollector 内核日志事件采集器（实现Collector接口）
+// 后台协程持续跟随 /dev/kmsg，按模式库分类后计数并输出结构化日志
+type KmsgCollector struct {
+	name            string
+	cfg             *KmsgConfig
+	metrics         metrics.KmsgCollectorMetrics
+	collectErrors   *prometheus.CounterVec
+	collectDuration *prometheus.HistogramVec
+
+	mu      sync.Mutex
+	file    *os.File
+	readErr error // 跟随协程异常退出时的错误，由 Collect 上报
+	closed  bool
+	done    chan struct{}
+}
+
+// NewKmsgCollector 创建内核日志事件采集器
+func NewKmsgCollector(cfg *KmsgConfig, metricFactory metrics.MetricFactory) *KmsgCollector {
+	return &KmsgCollector{
+		name: "kmsg-collector",
+		cfg:  cfg,
+		metrics: metrics.KmsgCollectorMetrics{
+			Events: metricFactory.NewKernelEventsTotal(),
+		},
+		collectErrors:   metricFactory.NewAgentCollectErrorsTotal(),
+		collectDuration: metricFactory.NewAgentCollectDurationSeconds(),
+		done:            make(chan struct{}),
+	}
+}
+
+// Name 返回采集器名称
+func (c *KmsgCollector) Name() string { return c.name }
+
+// Init 打开内核日志并启动跟随协程
+// 字符设备（/dev/kmsg）跳过历史消息只跟随新消息；普通文件和 fifo 从头读取
+func (c *KmsgCollector) Init() error {
+	stat, err := os.Stat(c.cfg.Path)
+	if err != nil {
+		logger.Error("failed to stat kernel log", zap.String("path", c.cfg.Path), zap.Error(err))
+		return fmt.Errorf("stat %s: %w", c.cfg.Path, err)
+	}
+	flag := os.O_RDONLY
+	if stat.Mode()&os.ModeNamedPipe != 0 {
+		// fifo 以读写方式打开：打开时不阻塞等待写端，写端全部关闭后也不会读到 EOF
+		flag = os.O_RDWR
+	}
+	file, err := os.OpenFile(c.cfg.Path, flag, 0)
+	if err != nil {
+		logger.Error("failed to open kernel log", zap.String("path", c.cfg.Path), zap.Error(err))
+		return fmt.Errorf("open %s: %w", c.cfg.Path, err)
+	}
+	if stat.Mode()&os.ModeCharDevice != 0 {
+		if _, err := file.Seek(0, io.SeekEnd); err != nil {
+			logger.Warn("failed to seek kernel log to end, history will be replayed", zap.String("path", c.cfg.Path), zap.Error(err))
+		}
+	}
+	// 普通文件读到末尾后需要轮询等待追加内容
+	follow := stat.Mode().IsRegular()
+
+	c.file = file
+	go c.follow(file, follow)
+	return nil
+}
+
+// Collect 计数在跟随协程中实时更新，这里只上报跟随协程的异常
+func (c *KmsgCollector) Collect(ctx context.Context) error {
+	start := time.Now()
+	defer func() {
+		c.collectDuration.WithLabelValues(c.name).Observe(time.Since(start).Seconds())
+	}()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.readErr != nil {
+		c.collectErrors.WithLabelValues(c.name).Inc()
+		return fmt.Errorf("kernel log reader stopped: %w", c.readErr)
+	}
+	return nil
+}
+
+// Close 关闭文件以唤醒阻塞中的读取，并等待跟随协程退出
+func (c *KmsgCollector) Close() error {
+	c.mu.Lock()
+	if c.closed || c.file == nil {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	err := c.file.Close()
+	<-c.done
+	return err
+}
+
+// follow 逐条读取内核日志记录
+func (c *KmsgCollector) follow(file *os.File, pollAtEOF bool) {
+	defer close(c.done)
+	reader := bufio.NewReader(file)
+	var partial string
+	for {
+		line, err := reader.ReadString('\n')
+		if err == nil {
+			c.handleRecord(partial + strings.TrimRight(line, "\n"))
+			partial = ""
+			continue
+		}
+
+		if c.isClosed() {
+			return
+		}
+		switch {
+		case errors.Is(err, syscall.EPIPE):
+			// 读取速度跟不上时内核环形缓冲区覆盖了旧消息，跳过后继续读取
+			logger.Warn("kernel log messages were overwritten before being read", zap.String("path", c.cfg.Path))
+		case errors.Is(err, io.EOF) && pollAtEOF:
+			// 普通文件：保留未写完的半行，等待追加
+			partial += line
+			time.Sleep(kmsgPollInterval)
+		default:
+			if errors.Is(err, io.EOF) {
+				err = fmt.Errorf("unexpected EOF on %s", c.cfg.Path)
+			}
+			logger.Error("kernel log reader stopped", zap.String("path", c.cfg.Path), zap.Error(err))
+			c.mu.Lock()
+			c.readErr = err
+			c.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (c *KmsgCollector) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// handleRecord 解析 /dev/kmsg 记录格式 "priority,seq,timestamp_us,flags;message"
+// 以空格开头的是上一条记录的附加字段（SUBSYSTEM=...），直接忽略；
+// 不带头部的行（普通文本文件）整行视为消息
+func (c *KmsgCollector) handleRecord(record string) {
+	if record == "" || strings.HasPrefix(record, " ") {
+		return
+	}
+	message := record
+	var monotonic time.Duration
+	var seq string
+	if header, msg, ok := strings.Cut(record, ";"); ok {
+		parts := strings.Split(header, ",")
+		if len(parts) >= 3 {
+			if _, err := strconv.Atoi(parts[0]); err == nil {
+				message = msg
+				seq = parts[1]
+				if us, err := strconv.ParseInt(parts[2], 10, 64); err == nil {
+					monotonic = time.Duration(us) * time.Microsecond
+				}
+			}
+		}
+	}
+
+	event, ok := ClassifyKmsg(message)
+	if !ok {
+		return
+	}
+	c.metrics.Events.WithLabelValues(event.Class).Inc()
+
+	fields := []zap.Field{
+		zap.String("class", event.Class),
+		zap.String("message", event.Message),
+	}
+	if seq != "" {
+		fields = append(fields, zap.String("seq", seq), zap.Duration("since_boot", monotonic))
+	}
+	keys := make([]string, 0, len(event.Fields))
+	for k := range event.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fields = append(fields, zap.String(k, event.Fields[k]))
+	}
+	logger.Warn("kernel event", fields...)
+}
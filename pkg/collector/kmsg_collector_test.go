@@ -0,0 +1,90 @@
+package collector_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/collector"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestClassifyKmsg(t *testing.T) {
+	cases := []struct {
+		message string
+		class   string
+		field   string
+		value   string
+	}{
+		{"Out of memory: Killed process 4242 (java) total-vm:8000kB, anon-rss:4000kB", "oom_kill", "comm", "java"},
+		{"Memory cgroup out of memory: Killed process 77 (stress) total-vm:1kB", "oom_kill", "pid", "77"},
+		{"INFO: task kworker/2:1:311 blocked for more than 120 seconds.", "hung_task", "seconds", "120"},
+		{"nginx[1234]: segfault at 0 ip 00007f00 sp 00007ffd error 4 in libc.so.6", "segfault", "comm", "nginx"},
+		{"EXT4-fs error (device sda1): ext4_find_entry:1455: inode #2: comm ls: reading directory lblock 0", "fs_error", "device", "sda1"},
+		{"XFS (dm-0): Metadata corruption detected at xfs_buf_ioend+0x51/0x1b0", "fs_error", "fs", "XFS"},
+		{"blk_update_request: I/O error, dev sdb, sector 2048 op 0x0:(READ)", "io_error", "device", "sdb"},
+		{"e1000e: eth0 NIC Link is Down", "link_flap", "state", "Down"},
+		{"mlx5_core 0000:3b:00.0 ens1f0: Link up", "link_flap", "interface", "ens1f0"},
+		{"mce: [Hardware Error]: Machine check events logged", "mce", "", ""},
+	}
+	for _, tc := range cases {
+		event, ok := collector.ClassifyKmsg(tc.message)
+		if !ok {
+			t.Errorf("ClassifyKmsg(%q) not classified, want %s", tc.message, tc.class)
+			continue
+		}
+		if event.Class != tc.class {
+			t.Errorf("ClassifyKmsg(%q) class = %s, want %s", tc.message, event.Class, tc.class)
+		}
+		if tc.field != "" && event.Fields[tc.field] != tc.value {
+			t.Errorf("ClassifyKmsg(%q) field %s = %q, want %q", tc.message, tc.field, event.Fields[tc.field], tc.value)
+		}
+	}
+
+	if _, ok := collector.ClassifyKmsg("Linux version 6.1.0 (gcc 12)"); ok {
+		t.Errorf("ordinary boot message should not be classified")
+	}
+}
+
+func TestKmsgCollectorFollowsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kmsg")
+	initial := "6,1,1000,-;Linux version 6.1.0\n" +
+		"3,2,2000,-;Out of memory: Killed process 10 (a) total-vm:1kB\n" +
+		" SUBSYSTEM=memory\n"
+	if err := os.WriteFile(path, []byte(initial), 0o644); err != nil {
+		t.Fatalf("write kmsg fixture: %v", err)
+	}
+
+	reg := prometheus.NewRegistry()
+	c := collector.NewKmsgCollector(&collector.KmsgConfig{Path: path}, *metrics.NewMetricFactory(metrics.NewPromRegistry(reg)))
+	if err := c.Init(); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer c.Close()
+
+	// 追加的消息需要被跟随读取
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open for append: %v", err)
+	}
+	_, _ = f.WriteString("3,3,3000,-;INFO: task jbd2/sda1-8:200 blocked for more than 120 seconds.\n")
+	_, _ = f.WriteString("3,4,4000,-;Out of memory: Killed process 11 (b) total-vm:1kB\n")
+	_ = f.Close()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		oom, _ := gaugeValue(t, reg, "kernel_events_total", map[string]string{"class": "oom_kill"})
+		hung, _ := gaugeValue(t, reg, "kernel_events_total", map[string]string{"class": "hung_task"})
+		if oom == 2 && hung == 1 {
+			if err := c.Collect(context.Background()); err != nil {
+				t.Fatalf("collect: %v", err)
+			}
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("kernel events were not counted before deadline")
+}
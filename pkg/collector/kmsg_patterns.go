@@ -0,0 +1,58 @@
+package collector
+
+import "regexp"
+
+// kmsgPattern 内核日志分类规则：正则命名分组会作为结构化字段输出到日志
+type kmsgPattern struct {
+	class string
+	re    *regexp.Regexp
+}
+
+// kmsgPatterns 内核日志模式库，按顺序匹配，命中第一条即停止
+var kmsgPatterns = []kmsgPattern{
+	// Out of memory: Killed process 1234 (java) total-vm:...
+	// Memory cgroup out of memory: Killed process 1234 (java) ...
+	{class: "oom_kill", re: regexp.MustCompile(`[Oo]ut of memory: Kill(?:ed)? process (?P<pid>\d+) \((?P<comm>[^)]*)\)`)},
+	// INFO: task kworker/0:1:123 blocked for more than 120 seconds.
+	{class: "hung_task", re: regexp.MustCompile(`task (?P<comm>\S+):(?P<pid>\d+) blocked for more than (?P<seconds>\d+) seconds`)},
+	// nginx[1234]: segfault at 0 ip 00007f... sp 00007ff... error 4 in libc.so
+	{class: "segfault", re: regexp.MustCompile(`(?P<comm>\S+)\[(?P<pid>\d+)\]: segfault at (?P<addr>[0-9a-fx]+)`)},
+	// EXT4-fs error (device sda1): ext4_find_entry:1455: inode #2: comm ls: reading directory lblock 0
+	{class: "fs_error", re: regexp.MustCompile(`(?P<fs>EXT[234])-fs (?:error|warning) \(device (?P<device>[^)]+)\)`)},
+	// XFS (dm-0): Metadata corruption detected at xfs_buf_ioend+0x51/0x1b0
+	// XFS (sdb1): log I/O error -5
+	{class: "fs_error", re: regexp.MustCompile(`(?P<fs>XFS) \((?P<device>[^)]+)\): .*(?:[Cc]orruption|error|Error|shutdown)`)},
+	// blk_update_request: I/O error, dev sda, sector 2048 op 0x0:(READ)
+	// Buffer I/O error on dev sdb1, logical block 0, async page read
+	{class: "io_error", re: regexp.MustCompile(`I/O error,? (?:on )?dev (?P<device>[^,\s]+)`)},
+	// e1000e: eth0 NIC Link is Down / mlx5_core 0000:3b:00.0 ens1f0: Link down / ixgbe ...: eth1 NIC Link is Up 10 Gbps
+	{class: "link_flap", re: regexp.MustCompile(`(?P<interface>[a-zA-Z0-9_.\-]+):? (?:NIC )?Link (?:is )?(?P<state>[Uu]p|[Dd]own)`)},
+	// mce: [Hardware Error]: Machine check events logged
+	// mce: [Hardware Error]: CPU 2: Machine Check: 0 Bank 5: be00000000800400
+	{class: "mce", re: regexp.MustCompile(`(?:mce: \[Hardware Error\]|Machine check events logged|EDAC MC\d+: .* error)`)},
+}
+
+// KmsgEvent 一条分类后的内核事件
+type KmsgEvent struct {
+	Class   string
+	Fields  map[string]string
+	Message string
+}
+
+// ClassifyKmsg 使用模式库对内核日志消息分类，未命中时返回 false
+func ClassifyKmsg(message string) (KmsgEvent, bool) {
+	for _, p := range kmsgPatterns {
+		match := p.re.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+		fields := make(map[string]string)
+		for i, name := range p.re.SubexpNames() {
+			if name != "" && match[i] != "" {
+				fields[name] = match[i]
+			}
+		}
+		return KmsgEvent{Class: p.class, Fields: fields, Message: message}, true
+	}
+	return KmsgEvent{}, false
+}
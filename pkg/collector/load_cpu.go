@@ -1,20 +1,63 @@
 package collector
 
 import (
-	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/procfs"
 	"go.uber.org/zap"
 	"math"
-	"os"
 	"runtime"
-	"strconv"
-	"strings"
 	"sync"
 	"time"
 )
 
-// LoadCalculator CPU负载计算器：不依赖gopsutil，原生实现1/5/15分钟负载滚动平均
+// 负载模式（proc.load_mode）
+const (
+	LoadModeKernel          = "kernel"            // 直接输出内核 /proc/loadavg 的 load1/5/15（与 uptime 一致）
+	LoadModeInstantRunQueue = "instant_run_queue" // 对 procs_running 瞬时运行队列做 1/5/15 分钟指数移动平均
+)
+
+// LoadAvg /proc/loadavg 内容："0.52 0.58 0.59 2/345 12345"
+type LoadAvg struct {
+	Load1, Load5, Load15 float64
+	Running              uint64 // 当前可运行（R 状态）的调度实体数
+	Total                uint64 // 系统中的调度实体（线程）总数
+}
+
+// parseLoadAvg 解析 /proc/loadavg（不分配内存）
+func parseLoadAvg(data []byte) (LoadAvg, error) {
+	var la LoadAvg
+	var field []byte
+	loads := [3]*float64{&la.Load1, &la.Load5, &la.Load15}
+	for _, dst := range loads {
+		field, data = procfs.NextField(data)
+		v, ok := procfs.ParseDecimal(field)
+		if !ok {
+			return LoadAvg{}, fmt.Errorf("invalid /proc/loadavg load field %q", field)
+		}
+		*dst = v
+	}
+	field, _ = procfs.NextField(data)
+	for i, c := range field {
+		if c != '/' {
+			continue
+		}
+		running, ok1 := procfs.ParseUint(field[:i])
+		total, ok2 := procfs.ParseUint(field[i+1:])
+		if !ok1 || !ok2 {
+			break
+		}
+		la.Running, la.Total = running, total
+		return la, nil
+	}
+	return LoadAvg{}, fmt.Errorf("invalid /proc/loadavg tasks field %q", field)
+}
+
+// LoadCalculator 瞬时运行队列（instant run-queue）负载计算器
+// 以 sampleCycle 为周期读取 /proc/stat 的 procs_running，自行做 1/5/15 分钟指数移动平均。
+// 注意：它不是内核的 load average（不含 D 状态任务、采样周期不同），只在 load_mode=instant_run_queue 时使用
 type LoadCalculator struct {
 	mu          sync.RWMutex  // 并发安全锁
 	sampleCycle time.Duration // 采样周期（默认1秒）
@@ -25,10 +68,15 @@ type LoadCalculator struct {
 	alpha5      float64       // 5分钟衰减系数（α=1-exp(-Δt/300)）
 	alpha15     float64       // 15分钟衰减系数（α=1-exp(-Δt/900)）
 	initialized bool          // 初始化标记（首次采样后完成）
+	buf         bytes.Buffer  // 读取 /proc/stat 的缓冲区（只在采样协程中使用）
+	fs          procfs.FS     // /proc 读取入口
+
+	stop chan struct{} // StopLoad 关闭后采样协程退出
+	done chan struct{} // 采样协程退出后关闭
 }
 
 // NewLoadCalculator 创建负载计算器(采样周期建议1秒)
-func NewLoadCalculator(sampleCycle time.Duration) (*LoadCalculator, error) {
+func NewLoadCalculator(sampleCycle time.Duration, fsys procfs.FS) (*LoadCalculator, error) {
 	if sampleCycle <= 0 {
 		return nil, fmt.Errorf("sample cycle must be positive (got %v)", sampleCycle)
 	}
@@ -36,22 +84,31 @@ func NewLoadCalculator(sampleCycle time.Duration) (*LoadCalculator, error) {
 	// 指数移动平均衰减系数，贴合linux内核逻辑
 	return &LoadCalculator{
 		sampleCycle: sampleCycle,
+		fs:          fsys,
 		alpha1:      1 - math.Exp(-dt/60),
 		alpha5:      1 - math.Exp(-dt/300),
 		alpha15:     1 - math.Exp(-dt/900),
 	}, nil
 }
 
-// StartLoad Start 启动后台采集携程(非阻塞)
+// StartLoad Start 启动后台采集携程(非阻塞)，通过 StopLoad 停止
 func (c *LoadCalculator) StartLoad() error {
 	if runtime.GOOS != "linux" {
 		return fmt.Errorf("load collection only supports Linux (current OS: %s)", runtime.GOOS)
 	}
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
 	go func() {
+		defer close(c.done)
 		ticker := time.NewTicker(c.sampleCycle)
 		defer ticker.Stop()
 
-		for range ticker.C {
+		for {
+			select {
+			case <-ticker.C:
+			case <-c.stop:
+				return
+			}
 			if err := c.sampleAndUpdate(); err != nil {
 				logger.Warn("load calculator sample failed", zap.Error(err))
 			}
@@ -60,6 +117,16 @@ func (c *LoadCalculator) StartLoad() error {
 	return nil
 }
 
+// StopLoad 停止后台采样并等待协程退出，未启动或已停止时直接返回
+func (c *LoadCalculator) StopLoad() {
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	<-c.done
+	c.stop = nil
+}
+
 // GetLoads 获取当前1/5/15分钟负载（线程安全）
 func (c *LoadCalculator) GetLoads() (load1, load5, load15 float64, initialized bool) {
 	c.mu.RLock()
@@ -67,7 +134,7 @@ func (c *LoadCalculator) GetLoads() (load1, load5, load15 float64, initialized b
 	return c.load1, c.load5, c.load15, c.initialized
 }
 
-// sampleAndUpdate 采样瞬时负载并更新滚动平均
+// sampleAndUpdate 采样瞬时运行队列长度并更新滚动平均
 func (c *LoadCalculator) sampleAndUpdate() error {
 	currentLoad, err := c.getCurrentLoad()
 	if err != nil {
@@ -90,21 +157,25 @@ func (c *LoadCalculator) sampleAndUpdate() error {
 	return nil
 }
 
-// getCurrentLoad 从/proc/loadavg读取瞬时负载
+// getCurrentLoad 从/proc/stat读取 procs_running（当前可运行的线程数）
 func (c *LoadCalculator) getCurrentLoad() (float64, error) {
-	open, err := os.Open("/proc/loadavg")
+	data, err := procfs.ReadFile(context.Background(), c.fs, procfs.ProcPath("stat"), &c.buf)
 	if err != nil {
-		return 0, fmt.Errorf("open /proc/loadavg: %w", err)
-	}
-	defer open.Close()
-
-	scanner := bufio.NewScanner(open)
-	if !scanner.Scan() {
-		return 0, fmt.Errorf("read /proc/loadavg: %w", scanner.Err())
+		return 0, fmt.Errorf("read /proc/stat: %w", err)
 	}
-	fields := strings.Fields(scanner.Text())
-	if len(fields) < 1 {
-		return 0, fmt.Errorf("invalid /proc/loadavg format: %s", scanner.Err())
+	for len(data) > 0 {
+		var line []byte
+		line, data = procfs.NextLine(data)
+		if !procfs.HasPrefix(line, "procs_running ") {
+			continue
+		}
+		_, rest := procfs.NextField(line)
+		field, _ := procfs.NextField(rest)
+		running, ok := procfs.ParseUint(field)
+		if !ok {
+			return 0, fmt.Errorf("invalid procs_running value %q", field)
+		}
+		return float64(running), nil
 	}
-	return strconv.ParseFloat(fields[0], 64)
+	return 0, fmt.Errorf("procs_running not found in /proc/stat")
 }
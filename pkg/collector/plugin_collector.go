@@ -0,0 +1,315 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/plugin"
+	"github.com/agent-collector/pkg/registers"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+)
+
+// PluginsConfig 进程外插件配置（collectors.plugins，协议见 pkg/plugin）
+type PluginsConfig struct {
+	Directory       string        `yaml:"directory" mapstructure:"directory" comment:"插件目录，目录中的每个可执行文件作为一个插件进程启动"`
+	Timeout         time.Duration `yaml:"timeout" mapstructure:"timeout" comment:"握手/describe/单次采集的超时，采集超时的插件会被杀掉并重启"`
+	RestartInitial  time.Duration `yaml:"restart_initial" mapstructure:"restart_initial" comment:"插件退出后首次重启前的等待时间，之后每次翻倍"`
+	RestartMax      time.Duration `yaml:"restart_max" mapstructure:"restart_max" comment:"重启等待时间上限；插件连续运行超过该时间后重新从 restart_initial 开始"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" mapstructure:"shutdown_timeout" comment:"停止时等待插件退出的时间，超时后杀掉"`
+}
+
+// Validate 插件目录不能为空，各超时必须为正数
+func (p *PluginsConfig) Validate() error {
+	if strings.TrimSpace(p.Directory) == "" {
+		return fmt.Errorf("plugins.directory cannot be empty when plugins collector is enabled")
+	}
+	if p.Timeout <= 0 || p.RestartInitial <= 0 || p.ShutdownTimeout <= 0 {
+		return fmt.Errorf("plugins: timeout, restart_initial and shutdown_timeout must be positive")
+	}
+	if p.RestartMax < p.RestartInitial {
+		return fmt.Errorf("plugins.restart_max (%s) must not be less than restart_initial (%s)", p.RestartMax, p.RestartInitial)
+	}
+	return nil
+}
+
+func init() {
+	registers.RegisterFactory(registers.Factory{
+		Name:        "plugins",
+		Description: "out-of-process plugins from a directory (插件目录中的进程外采集器)",
+		NewConfig: func() any {
+			return &PluginsConfig{
+				Directory:       "/etc/agent-collector/plugins",
+				Timeout:         10 * time.Second,
+				RestartInitial:  time.Second,
+				RestartMax:      time.Minute,
+				ShutdownTimeout: 5 * time.Second,
+			}
+		},
+		New: func(cfg any, deps registers.Deps) (registers.Collector, error) {
+			return NewPluginCollector(cfg.(*PluginsConfig), deps.MetricFactory), nil
+		},
+	})
+}
+
+// pluginRunner 单个插件的当前进程（未运行或等待重启时 proc 为 nil）
+type pluginRunner struct {
+	name string // 可执行文件名，作为 plugin 标签
+	path string
+
+	mu   sync.Mutex
+	proc *plugin.Process
+	desc plugin.Description
+}
+
+func (r *pluginRunner) set(proc *plugin.Process, desc plugin.Description) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.proc, r.desc = proc, desc
+}
+
+func (r *pluginRunner) get() (*plugin.Process, plugin.Description) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.proc, r.desc
+}
+
+// PluginCollector 进程外插件采集器（实现Collector接口）
+// Init 时启动插件目录中的所有可执行文件并各由一个协程监管，插件退出后按指数退避重启；
+// Collect 并发向所有运行中的插件发送 collect 请求，合并返回的指标族
+type PluginCollector struct {
+	name            string
+	cfg             *PluginsConfig
+	metrics         metrics.PluginCollectorMetrics
+	collectErrors   *prometheus.CounterVec
+	collectDuration *prometheus.HistogramVec
+
+	plugins []*pluginRunner
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewPluginCollector 创建插件采集器
+func NewPluginCollector(cfg *PluginsConfig, metricFactory metrics.MetricFactory) *PluginCollector {
+	return &PluginCollector{
+		name: "plugins-collector",
+		cfg:  cfg,
+		metrics: metrics.PluginCollectorMetrics{
+			Up:              metricFactory.NewPluginUp(),
+			Restarts:        metricFactory.NewPluginRestartsTotal(),
+			CollectDuration: metricFactory.NewPluginCollectDurationSeconds(),
+			CollectError:    metricFactory.NewPluginCollectError(),
+			Families:        metricFactory.NewFamilyCollector(),
+		},
+		collectErrors:   metricFactory.NewAgentCollectErrorsTotal(),
+		collectDuration: metricFactory.NewAgentCollectDurationSeconds(),
+	}
+}
+
+// Name 返回采集器名称
+func (c *PluginCollector) Name() string { return c.name }
+
+// Init 扫描插件目录（只在启动时扫描一次）并为每个插件启动监管协程
+func (c *PluginCollector) Init() error {
+	entries, err := os.ReadDir(c.cfg.Directory)
+	if err != nil {
+		logger.Error("failed to read plugin directory", zap.String("path", c.cfg.Directory), zap.Error(err))
+		return fmt.Errorf("read plugin directory %s: %w", c.cfg.Directory, err)
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(c.cfg.Directory, e.Name())
+		// Stat 跟随符号链接，只启动普通的可执行文件
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		c.plugins = append(c.plugins, &pluginRunner{name: e.Name(), path: path})
+	}
+	if len(c.plugins) == 0 {
+		logger.Warn("no executable plugins found", zap.String("path", c.cfg.Directory))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	for _, r := range c.plugins {
+		c.wg.Add(1)
+		go c.supervise(ctx, r)
+	}
+	return nil
+}
+
+// supervise 启动插件并在其退出后按指数退避重启，ctx 取消后通知插件退出
+func (c *PluginCollector) supervise(ctx context.Context, r *pluginRunner) {
+	defer c.wg.Done()
+	failures := 0
+	for {
+		started := time.Now()
+		proc, err := c.launch(ctx, r)
+		if err != nil {
+			logger.Error("failed to start plugin", zap.String("plugin", r.name), zap.Error(err))
+		} else {
+			c.metrics.Up.WithLabelValues(r.name).Set(1)
+			select {
+			case <-proc.Exited():
+				logger.Warn("plugin exited", zap.String("plugin", r.name), zap.Duration("uptime", time.Since(started)), zap.Error(proc.Err()))
+			case <-ctx.Done():
+				r.set(nil, plugin.Description{})
+				c.metrics.Up.WithLabelValues(r.name).Set(0)
+				if err := proc.Stop(c.cfg.ShutdownTimeout); err != nil {
+					logger.Warn("failed to stop plugin gracefully", zap.String("plugin", r.name), zap.Error(err))
+				}
+				return
+			}
+		}
+		r.set(nil, plugin.Description{})
+		c.metrics.Up.WithLabelValues(r.name).Set(0)
+
+		// 稳定运行过一段时间后再退出，退避从初始值重新开始
+		if time.Since(started) > c.cfg.RestartMax {
+			failures = 0
+		}
+		delay := c.restartDelay(failures)
+		failures++
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		c.metrics.Restarts.WithLabelValues(r.name).Inc()
+		logger.Info("restarting plugin", zap.String("plugin", r.name), zap.Duration("backoff", delay))
+	}
+}
+
+// launch 启动插件进程并完成握手和 describe，失败时杀掉进程
+func (c *PluginCollector) launch(ctx context.Context, r *pluginRunner) (*plugin.Process, error) {
+	proc, err := plugin.Start(r.path)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+	desc, err := handshake(ctx, proc)
+	if err != nil {
+		_ = proc.Kill()
+		<-proc.Exited()
+		return nil, err
+	}
+	r.set(proc, desc)
+	logger.Info("plugin started",
+		zap.String("plugin", r.name),
+		zap.String("name", desc.Name),
+		zap.String("version", desc.Version),
+		zap.Int("pid", proc.Pid()),
+		zap.Int("declared_metrics", len(desc.Metrics)))
+	return proc, nil
+}
+
+func handshake(ctx context.Context, proc *plugin.Process) (plugin.Description, error) {
+	if err := proc.Handshake(ctx); err != nil {
+		return plugin.Description{}, fmt.Errorf("handshake: %w", err)
+	}
+	desc, err := proc.Describe(ctx)
+	if err != nil {
+		return plugin.Description{}, fmt.Errorf("describe: %w", err)
+	}
+	return desc, nil
+}
+
+// restartDelay 第 n 次连续失败后的重启等待时间：restart_initial * 2^n，不超过 restart_max
+func (c *PluginCollector) restartDelay(failures int) time.Duration {
+	d := c.cfg.RestartInitial
+	for i := 0; i < failures && d < c.cfg.RestartMax; i++ {
+		d *= 2
+	}
+	return min(d, c.cfg.RestartMax)
+}
+
+// Collect 并发请求所有运行中的插件，合并返回的指标族；等待重启的插件本轮不输出指标
+func (c *PluginCollector) Collect(ctx context.Context) error {
+	start := time.Now()
+	defer func() {
+		c.collectDuration.WithLabelValues(c.name).Observe(time.Since(start).Seconds())
+	}()
+
+	results := make([]map[string]*dto.MetricFamily, len(c.plugins))
+	var wg sync.WaitGroup
+	for i, r := range c.plugins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.collectPlugin(ctx, r)
+		}()
+	}
+	wg.Wait()
+
+	// c.plugins 按文件名排序（os.ReadDir 的顺序），冲突时名称靠前的插件优先
+	merged := newFamilyMerger()
+	for i, families := range results {
+		if families == nil {
+			continue
+		}
+		if err := merged.add(families); err != nil {
+			logger.Warn("plugin output conflicts with other plugins", zap.String("plugin", c.plugins[i].name), zap.Error(err))
+			c.metrics.CollectError.WithLabelValues(c.plugins[i].name).Set(1)
+		}
+	}
+	c.metrics.Families.Update(merged.families())
+	return nil
+}
+
+// collectPlugin 向单个插件发送 collect 请求并解析结果，失败时返回 nil
+func (c *PluginCollector) collectPlugin(ctx context.Context, r *pluginRunner) map[string]*dto.MetricFamily {
+	proc, desc := r.get()
+	if proc == nil {
+		return nil
+	}
+	cctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	text, err := proc.Collect(cctx)
+	c.metrics.CollectDuration.WithLabelValues(r.name).Set(time.Since(start).Seconds())
+	if err == nil {
+		var families map[string]*dto.MetricFamily
+		if families, err = ParsePromText(strings.NewReader(text)); err == nil {
+			for name := range families {
+				if !desc.Declares(name) {
+					logger.Debug("dropping metric not declared by plugin", zap.String("plugin", r.name), zap.String("metric", name))
+					delete(families, name)
+				}
+			}
+			c.metrics.CollectError.WithLabelValues(r.name).Set(0)
+			return families
+		}
+	}
+
+	c.metrics.CollectError.WithLabelValues(r.name).Set(1)
+	c.collectErrors.WithLabelValues(c.name).Inc()
+	logger.Warn("plugin collect failed", zap.String("plugin", r.name), zap.Error(err))
+	// 插件自身未在超时内响应（而不是本轮采集被取消）：视为卡死，杀掉后由 supervise 重启
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		_ = proc.Kill()
+	}
+	return nil
+}
+
+// Close 通知所有插件退出并等待监管协程结束
+func (c *PluginCollector) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+		c.wg.Wait()
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package collector_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/collector"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// buildTestPlugin 编译 testdata/testplugin 并在 dir 中为每个插件写一个带参数的启动脚本
+func buildTestPlugin(t *testing.T, dir string, plugins map[string][]string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin wrappers are shell scripts")
+	}
+	bin := filepath.Join(t.TempDir(), "testplugin")
+	if out, err := exec.Command("go", "build", "-o", bin, "./testdata/testplugin").CombinedOutput(); err != nil {
+		t.Fatalf("build test plugin: %v\n%s", err, out)
+	}
+	for name, args := range plugins {
+		script := fmt.Sprintf("#!/bin/sh\nexec %s %s\n", bin, strings.Join(args, " "))
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPluginCollector(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(t.TempDir(), "alpha-shutdown")
+	buildTestPlugin(t, dir, map[string][]string{
+		"alpha":  {"-name", "alpha", "-value", "1", "-undeclared", "-shutdown-marker", marker},
+		"crashy": {"-name", "crashy", "-value", "2", "-exit-after", "1"},
+	})
+	// 不可执行的文件不会被当作插件启动
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := prometheus.NewRegistry()
+	c := collector.NewPluginCollector(&collector.PluginsConfig{
+		Directory:       dir,
+		Timeout:         5 * time.Second,
+		RestartInitial:  20 * time.Millisecond,
+		RestartMax:      200 * time.Millisecond,
+		ShutdownTimeout: 5 * time.Second,
+	}, *metrics.NewMetricFactory(metrics.NewPromRegistry(reg)))
+	if err := c.Init(); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	closed := false
+	defer func() {
+		if !closed {
+			_ = c.Close()
+		}
+	}()
+
+	up := func(name string) bool {
+		v, ok := gaugeValue(t, reg, "plugin_up", map[string]string{"plugin": name})
+		return ok && v == 1
+	}
+	waitFor(t, "both plugins up", func() bool { return up("alpha") && up("crashy") })
+	if _, ok := gaugeValue(t, reg, "plugin_up", map[string]string{"plugin": "README"}); ok {
+		t.Error("non-executable file was started as a plugin")
+	}
+
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	for name, want := range map[string]float64{"alpha": 1, "crashy": 2} {
+		if v, ok := gaugeValue(t, reg, "testplugin_value", map[string]string{"plugin": name}); !ok || v != want {
+			t.Errorf("testplugin_value{plugin=%q} = %v (found %v), want %v", name, v, ok, want)
+		}
+	}
+	if _, ok := gaugeValue(t, reg, "testplugin_undeclared", nil); ok {
+		t.Error("metric not declared in describe was merged into the registry")
+	}
+
+	// crashy 在第二次采集时退出：本轮只剩 alpha 的指标，之后被重启并恢复
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if v, _ := gaugeValue(t, reg, "plugin_collect_error", map[string]string{"plugin": "crashy"}); v != 1 {
+		t.Errorf("plugin_collect_error{plugin=crashy} = %v, want 1 after crash", v)
+	}
+	waitFor(t, "crashy restarted", func() bool {
+		v, _ := gaugeValue(t, reg, "plugin_restarts_total", map[string]string{"plugin": "crashy"})
+		return v >= 1 && up("crashy")
+	})
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if v, ok := gaugeValue(t, reg, "testplugin_value", map[string]string{"plugin": "crashy"}); !ok || v != 2 {
+		t.Errorf("testplugin_value{plugin=crashy} after restart = %v (found %v), want 2", v, ok)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	closed = true
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("alpha did not shut down gracefully: %v", err)
+	}
+	if up("alpha") {
+		t.Error("plugin_up{plugin=alpha} still 1 after Close")
+	}
+}
@@ -0,0 +1,506 @@
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/registers"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// probeMaxBodyBytes 正则匹配时最多读取的响应体大小
+const probeMaxBodyBytes = 1 << 20
+
+// probePhases 探测可能记录的阶段，本次未经历的阶段在发布时删除对应序列
+var probePhases = []string{"resolve", "connect", "tls", "first_byte"}
+
+// probeResult 单次探测结果
+type probeResult struct {
+	success    bool
+	duration   time.Duration
+	phases     map[string]time.Duration // resolve/connect/tls/first_byte
+	statusCode int
+	bodyMatch  *bool
+	dnsAnswers int
+	certExpiry time.Time
+	err        error
+}
+
+// ProbeConfig 依赖可达性探测配置（collectors.probe）
+type ProbeConfig struct {
+	Timeout time.Duration       `yaml:"timeout" mapstructure:"timeout" comment:"默认探测超时（目标未单独配置时使用）"`
+	Targets []ProbeTargetConfig `yaml:"targets" mapstructure:"targets" comment:"探测目标列表"`
+}
+
+// ProbeTargetConfig 单个探测目标
+type ProbeTargetConfig struct {
+	Name               string        `yaml:"name" mapstructure:"name" comment:"目标名称（指标target标签，唯一）"`
+	Type               string        `yaml:"type" mapstructure:"type" comment:"探测类型（tcp/http/dns）"`
+	Target             string        `yaml:"target" mapstructure:"target" comment:"tcp: host:port，http: URL，dns: 待解析域名"`
+	Timeout            time.Duration `yaml:"timeout" mapstructure:"timeout" comment:"探测超时"`
+	TLS                bool          `yaml:"tls" mapstructure:"tls" comment:"tcp探测是否进行TLS握手"`
+	InsecureSkipVerify bool          `yaml:"insecure_skip_verify" mapstructure:"insecure_skip_verify" comment:"是否跳过证书校验"`
+	Method             string        `yaml:"method" mapstructure:"method" comment:"http请求方法" default:"GET"`
+	ValidStatusCodes   []int         `yaml:"valid_status_codes" mapstructure:"valid_status_codes" comment:"http成功状态码，为空时2xx视为成功"`
+	BodyRegex          string        `yaml:"body_regex" mapstructure:"body_regex" comment:"http响应体需要匹配的正则"`
+	DNSServer          string        `yaml:"dns_server" mapstructure:"dns_server" comment:"dns服务器（host:port），为空使用系统解析"`
+	QueryType          string        `yaml:"query_type" mapstructure:"query_type" comment:"dns查询类型（A/AAAA/MX/TXT/NS/CNAME）" default:"A"`
+}
+
+// Validate 校验探测目标，并为未配置的超时/方法/查询类型补默认值
+func (p *ProbeConfig) Validate() error {
+	if len(p.Targets) == 0 {
+		return fmt.Errorf("probe.targets cannot be empty when probe collector is enabled")
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = 5 * time.Second
+	}
+	seen := map[string]bool{}
+	for i := range p.Targets {
+		t := &p.Targets[i]
+		if strings.TrimSpace(t.Name) == "" {
+			return fmt.Errorf("probe.targets[%d].name cannot be empty", i)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("probe.targets duplicated name: %q", t.Name)
+		}
+		seen[t.Name] = true
+		if strings.TrimSpace(t.Target) == "" {
+			return fmt.Errorf("probe.targets[%s].target cannot be empty", t.Name)
+		}
+		if t.Timeout <= 0 {
+			t.Timeout = p.Timeout
+		}
+		switch t.Type {
+		case "tcp":
+			if _, _, err := net.SplitHostPort(t.Target); err != nil {
+				return fmt.Errorf("probe.targets[%s].target must be host:port: %w", t.Name, err)
+			}
+		case "http":
+			if !strings.HasPrefix(t.Target, "http://") && !strings.HasPrefix(t.Target, "https://") {
+				return fmt.Errorf("probe.targets[%s].target must be an http(s) URL, got %s", t.Name, t.Target)
+			}
+			if t.Method == "" {
+				t.Method = "GET"
+			}
+			if t.BodyRegex != "" {
+				if _, err := regexp.Compile(t.BodyRegex); err != nil {
+					return fmt.Errorf("probe.targets[%s].body_regex invalid: %w", t.Name, err)
+				}
+			}
+		case "dns":
+			if t.QueryType == "" {
+				t.QueryType = "A"
+			}
+			t.QueryType = strings.ToUpper(t.QueryType)
+			switch t.QueryType {
+			case "A", "AAAA", "MX", "TXT", "NS", "CNAME":
+			default:
+				return fmt.Errorf("probe.targets[%s].query_type unsupported: %s", t.Name, t.QueryType)
+			}
+			if t.DNSServer != "" {
+				if _, _, err := net.SplitHostPort(t.DNSServer); err != nil {
+					return fmt.Errorf("probe.targets[%s].dns_server must be host:port: %w", t.Name, err)
+				}
+			}
+		default:
+			return fmt.Errorf("probe.targets[%s].type must be tcp/http/dns, got %q", t.Name, t.Type)
+		}
+	}
+	return nil
+}
+
+func init() {
+	registers.RegisterFactory(registers.Factory{
+		Name:        "probe",
+		Description: "TCP/HTTP/DNS probes, targets configured in the config file (依赖探测，目标在配置文件中配置)",
+		NewConfig:   func() any { return &ProbeConfig{Timeout: 5 * time.Second} },
+		New: func(cfg any, deps registers.Deps) (registers.Collector, error) {
+			return NewProbeCollector(cfg.(*ProbeConfig), deps.MetricFactory), nil
+		},
+	})
+}
+
+// ProbeCollector 依赖探测采集器（实现Collector接口）
+// 每次采集并发探测所有目标，单个目标超时不影响其他目标
+type ProbeCollector struct {
+	name            string
+	cfg             *ProbeConfig
+	metrics         metrics.ProbeCollectorMetrics
+	collectErrors   *prometheus.CounterVec
+	collectDuration *prometheus.HistogramVec
+
+	bodyRegex map[string]*regexp.Regexp // 目标名称 -> 预编译的响应体正则
+}
+
+// NewProbeCollector 创建依赖探测采集器
+func NewProbeCollector(cfg *ProbeConfig, metricFactory metrics.MetricFactory) *ProbeCollector {
+	return &ProbeCollector{
+		name: "probe-collector",
+		cfg:  cfg,
+		metrics: metrics.ProbeCollectorMetrics{
+			Success:        metricFactory.NewProbeSuccess(),
+			Duration:       metricFactory.NewProbeDurationSeconds(),
+			PhaseDuration:  metricFactory.NewProbePhaseDurationSeconds(),
+			HTTPStatusCode: metricFactory.NewProbeHTTPStatusCode(),
+			HTTPBodyMatch:  metricFactory.NewProbeHTTPBodyMatch(),
+			DNSAnswers:     metricFactory.NewProbeDNSAnswers(),
+			CertExpiry:     metricFactory.NewProbeTLSCertExpiry(),
+		},
+		collectErrors:   metricFactory.NewAgentCollectErrorsTotal(),
+		collectDuration: metricFactory.NewAgentCollectDurationSeconds(),
+		bodyRegex:       make(map[string]*regexp.Regexp),
+	}
+}
+
+// Name 返回采集器名称
+func (c *ProbeCollector) Name() string { return c.name }
+
+// Schedule 声明调度参数：间隔沿用全局配置（短于超时时由调度器提高到超时），超时取最慢目标的探测超时再留 1s 发布结果
+// 避免调度器的 deadline 先于单个探测的超时到期，导致慢目标总是被记为失败
+func (c *ProbeCollector) Schedule() (interval, timeout time.Duration) {
+	for _, t := range c.cfg.Targets {
+		timeout = max(timeout, t.Timeout)
+	}
+	if timeout > 0 {
+		timeout += time.Second
+	}
+	return 0, timeout
+}
+
+// Init 预编译响应体正则
+func (c *ProbeCollector) Init() error {
+	for _, t := range c.cfg.Targets {
+		if t.BodyRegex == "" {
+			continue
+		}
+		re, err := regexp.Compile(t.BodyRegex)
+		if err != nil {
+			return fmt.Errorf("probe target %s body_regex: %w", t.Name, err)
+		}
+		c.bodyRegex[t.Name] = re
+	}
+	return nil
+}
+
+// Collect 并发探测所有目标并更新指标
+func (c *ProbeCollector) Collect(ctx context.Context) error {
+	start := time.Now()
+	defer func() {
+		c.collectDuration.WithLabelValues(c.name).Observe(time.Since(start).Seconds())
+	}()
+
+	results := make([]probeResult, len(c.cfg.Targets))
+	var wg sync.WaitGroup
+	for i, t := range c.cfg.Targets {
+		wg.Add(1)
+		go func(i int, t ProbeTargetConfig) {
+			defer wg.Done()
+			pctx, cancel := context.WithTimeout(ctx, t.Timeout)
+			defer cancel()
+			results[i] = c.probe(pctx, t)
+		}(i, t)
+	}
+	wg.Wait()
+
+	for i, t := range c.cfg.Targets {
+		c.publish(t, results[i])
+	}
+	return nil
+}
+
+// Close 无需释放资源
+func (c *ProbeCollector) Close() error { return nil }
+
+// probe 按类型分发探测
+func (c *ProbeCollector) probe(ctx context.Context, t ProbeTargetConfig) probeResult {
+	start := time.Now()
+	var r probeResult
+	switch t.Type {
+	case "tcp":
+		r = probeTCP(ctx, t)
+	case "http":
+		r = probeHTTP(ctx, t, c.bodyRegex[t.Name])
+	case "dns":
+		r = probeDNS(ctx, t)
+	default:
+		r = probeResult{err: fmt.Errorf("unsupported probe type %q", t.Type)}
+	}
+	r.duration = time.Since(start)
+	return r
+}
+
+// publish 写入单个目标的探测结果
+// 本次没有得到的阶段耗时、证书过期时间和响应体匹配结果要删除，避免目标失败后仍暴露上一次成功时的值
+func (c *ProbeCollector) publish(t ProbeTargetConfig, r probeResult) {
+	c.metrics.Success.WithLabelValues(t.Name, t.Type).Set(boolToFloat(r.success))
+	c.metrics.Duration.WithLabelValues(t.Name, t.Type).Set(r.duration.Seconds())
+	for _, phase := range probePhases {
+		if d, ok := r.phases[phase]; ok {
+			c.metrics.PhaseDuration.WithLabelValues(t.Name, t.Type, phase).Set(d.Seconds())
+		} else {
+			c.metrics.PhaseDuration.DeleteLabelValues(t.Name, t.Type, phase)
+		}
+	}
+	switch t.Type {
+	case "http":
+		c.metrics.HTTPStatusCode.WithLabelValues(t.Name).Set(float64(r.statusCode))
+		if r.bodyMatch != nil {
+			c.metrics.HTTPBodyMatch.WithLabelValues(t.Name).Set(boolToFloat(*r.bodyMatch))
+		} else {
+			c.metrics.HTTPBodyMatch.DeleteLabelValues(t.Name)
+		}
+	case "dns":
+		c.metrics.DNSAnswers.WithLabelValues(t.Name).Set(float64(r.dnsAnswers))
+	}
+	if !r.certExpiry.IsZero() {
+		c.metrics.CertExpiry.WithLabelValues(t.Name).Set(float64(r.certExpiry.Unix()))
+	} else {
+		c.metrics.CertExpiry.DeleteLabelValues(t.Name)
+	}
+
+	if r.err != nil {
+		logger.Warn("probe failed",
+			zap.String("target", t.Name),
+			zap.String("type", t.Type),
+			zap.String("address", t.Target),
+			zap.Duration("duration", r.duration),
+			zap.Error(r.err))
+		return
+	}
+	logger.Debug("probe succeeded", zap.String("target", t.Name), zap.String("type", t.Type), zap.Duration("duration", r.duration))
+}
+
+// probeTCP 解析地址并建立 TCP 连接，配置了 tls 时继续完成握手
+func probeTCP(ctx context.Context, t ProbeTargetConfig) probeResult {
+	r := probeResult{phases: make(map[string]time.Duration)}
+	host, port, err := net.SplitHostPort(t.Target)
+	if err != nil {
+		r.err = err
+		return r
+	}
+
+	start := time.Now()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	r.phases["resolve"] = time.Since(start)
+	if err != nil {
+		r.err = fmt.Errorf("resolve %s: %w", host, err)
+		return r
+	}
+	if len(addrs) == 0 {
+		r.err = fmt.Errorf("resolve %s: no addresses", host)
+		return r
+	}
+
+	start = time.Now()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(addrs[0].IP.String(), port))
+	r.phases["connect"] = time.Since(start)
+	if err != nil {
+		r.err = fmt.Errorf("connect %s: %w", t.Target, err)
+		return r
+	}
+	defer conn.Close()
+
+	if t.TLS {
+		start = time.Now()
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: t.InsecureSkipVerify})
+		err = tlsConn.HandshakeContext(ctx)
+		r.phases["tls"] = time.Since(start)
+		if err != nil {
+			r.err = fmt.Errorf("tls handshake %s: %w", t.Target, err)
+			return r
+		}
+		r.certExpiry = earliestExpiry(tlsConn.ConnectionState().PeerCertificates)
+	}
+	r.success = true
+	return r
+}
+
+// probeHTTP 发起一次不复用连接的 HTTP 请求，通过 httptrace 记录各阶段耗时
+func probeHTTP(ctx context.Context, t ProbeTargetConfig, bodyRegex *regexp.Regexp) (r probeResult) {
+	// trace 回调可能在 Do 返回后仍被调用（如并发拨号），返回前在锁内复制阶段耗时
+	var mu sync.Mutex
+	phases := make(map[string]time.Duration)
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		r.phases = make(map[string]time.Duration, len(phases))
+		for k, v := range phases {
+			r.phases[k] = v
+		}
+	}()
+
+	// first_byte 从拿到连接（建连和 TLS 握手完成）开始计时，只包含发送请求和服务端处理的耗时
+	var dnsStart, connectStart, tlsStart, gotConn time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { mu.Lock(); dnsStart = time.Now(); mu.Unlock() },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			mu.Lock()
+			phases["resolve"] = time.Since(dnsStart)
+			mu.Unlock()
+		},
+		ConnectStart: func(string, string) { mu.Lock(); connectStart = time.Now(); mu.Unlock() },
+		ConnectDone: func(string, string, error) {
+			mu.Lock()
+			phases["connect"] = time.Since(connectStart)
+			mu.Unlock()
+		},
+		TLSHandshakeStart: func() { mu.Lock(); tlsStart = time.Now(); mu.Unlock() },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			mu.Lock()
+			phases["tls"] = time.Since(tlsStart)
+			mu.Unlock()
+		},
+		GotConn: func(httptrace.GotConnInfo) { mu.Lock(); gotConn = time.Now(); mu.Unlock() },
+		GotFirstResponseByte: func() {
+			mu.Lock()
+			phases["first_byte"] = time.Since(gotConn)
+			mu.Unlock()
+		},
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), t.Method, t.Target, nil)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			DisableKeepAlives: true,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: t.InsecureSkipVerify},
+		},
+		// 不跟随重定向，按目标本身的状态码判断
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		r.err = fmt.Errorf("http request %s: %w", t.Target, err)
+		return r
+	}
+	defer resp.Body.Close()
+
+	r.statusCode = resp.StatusCode
+	if resp.TLS != nil {
+		r.certExpiry = earliestExpiry(resp.TLS.PeerCertificates)
+	}
+	r.success = validStatus(resp.StatusCode, t.ValidStatusCodes)
+	if !r.success {
+		r.err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if bodyRegex != nil {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, probeMaxBodyBytes))
+		if err != nil {
+			r.success = false
+			r.err = fmt.Errorf("read body: %w", err)
+			return r
+		}
+		matched := bodyRegex.Match(body)
+		r.bodyMatch = &matched
+		if !matched {
+			r.success = false
+			r.err = fmt.Errorf("body does not match %q", bodyRegex.String())
+		}
+	} else {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, probeMaxBodyBytes))
+	}
+	return r
+}
+
+// probeDNS 按查询类型解析域名，至少返回一条记录视为成功
+func probeDNS(ctx context.Context, t ProbeTargetConfig) probeResult {
+	r := probeResult{phases: make(map[string]time.Duration)}
+	resolver := net.DefaultResolver
+	if t.DNSServer != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, t.DNSServer)
+			},
+		}
+	}
+
+	start := time.Now()
+	var err error
+	switch t.QueryType {
+	case "A", "AAAA":
+		network := "ip4"
+		if t.QueryType == "AAAA" {
+			network = "ip6"
+		}
+		var ips []net.IP
+		ips, err = resolver.LookupIP(ctx, network, t.Target)
+		r.dnsAnswers = len(ips)
+	case "MX":
+		var mx []*net.MX
+		mx, err = resolver.LookupMX(ctx, t.Target)
+		r.dnsAnswers = len(mx)
+	case "TXT":
+		var txt []string
+		txt, err = resolver.LookupTXT(ctx, t.Target)
+		r.dnsAnswers = len(txt)
+	case "NS":
+		var ns []*net.NS
+		ns, err = resolver.LookupNS(ctx, t.Target)
+		r.dnsAnswers = len(ns)
+	case "CNAME":
+		var cname string
+		cname, err = resolver.LookupCNAME(ctx, t.Target)
+		if cname != "" {
+			r.dnsAnswers = 1
+		}
+	default:
+		err = fmt.Errorf("unsupported query type %q", t.QueryType)
+	}
+	r.phases["resolve"] = time.Since(start)
+
+	if err != nil {
+		r.err = fmt.Errorf("dns %s %s: %w", t.QueryType, t.Target, err)
+		return r
+	}
+	r.success = r.dnsAnswers > 0
+	if !r.success {
+		r.err = fmt.Errorf("dns %s %s: empty answer", t.QueryType, t.Target)
+	}
+	return r
+}
+
+// validStatus 未配置成功状态码时 2xx 视为成功
+func validStatus(code int, valid []int) bool {
+	if len(valid) == 0 {
+		return code >= 200 && code < 300
+	}
+	for _, v := range valid {
+		if v == code {
+			return true
+		}
+	}
+	return false
+}
+
+// earliestExpiry 返回证书链中最早的过期时间
+func earliestExpiry(certs []*x509.Certificate) time.Time {
+	var earliest time.Time
+	for _, cert := range certs {
+		if earliest.IsZero() || cert.NotAfter.Before(earliest) {
+			earliest = cert.NotAfter
+		}
+	}
+	return earliest
+}
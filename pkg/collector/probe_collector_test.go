@@ -0,0 +1,209 @@
+package collector_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/collector"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newProbeCollector(t *testing.T, targets []collector.ProbeTargetConfig) (*collector.ProbeCollector, *prometheus.Registry) {
+	t.Helper()
+	cfg := &collector.ProbeConfig{Timeout: 2 * time.Second, Targets: targets}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("validate probe config: %v", err)
+	}
+	reg := prometheus.NewRegistry()
+	c := collector.NewProbeCollector(cfg, *metrics.NewMetricFactory(metrics.NewPromRegistry(reg)))
+	if err := c.Init(); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	return c, reg
+}
+
+func TestProbeHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+	tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer tlsSrv.Close()
+
+	c, reg := newProbeCollector(t, []collector.ProbeTargetConfig{
+		{Name: "ok", Type: "http", Target: srv.URL + "/health", BodyRegex: `"status":"ok"`},
+		{Name: "mismatch", Type: "http", Target: srv.URL + "/health", BodyRegex: "degraded"},
+		{Name: "down", Type: "http", Target: srv.URL + "/down"},
+		{Name: "down-allowed", Type: "http", Target: srv.URL + "/down", ValidStatusCodes: []int{503}},
+		{Name: "tls", Type: "http", Target: tlsSrv.URL, InsecureSkipVerify: true},
+	})
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	cases := []struct {
+		target  string
+		success float64
+		status  float64
+	}{
+		{"ok", 1, 200},
+		{"mismatch", 0, 200},
+		{"down", 0, 503},
+		{"down-allowed", 1, 503},
+		{"tls", 1, 200},
+	}
+	for _, tc := range cases {
+		if v, _ := gaugeValue(t, reg, "probe_success", map[string]string{"target": tc.target}); v != tc.success {
+			t.Errorf("probe_success{target=%q} = %v, want %v", tc.target, v, tc.success)
+		}
+		if v, _ := gaugeValue(t, reg, "probe_http_status_code", map[string]string{"target": tc.target}); v != tc.status {
+			t.Errorf("probe_http_status_code{target=%q} = %v, want %v", tc.target, v, tc.status)
+		}
+	}
+	if v, _ := gaugeValue(t, reg, "probe_http_body_match", map[string]string{"target": "mismatch"}); v != 0 {
+		t.Errorf("probe_http_body_match{target=mismatch} = %v, want 0", v)
+	}
+	if _, ok := gaugeValue(t, reg, "probe_phase_duration_seconds", map[string]string{"target": "ok", "phase": "first_byte"}); !ok {
+		t.Errorf("missing first_byte phase for target ok")
+	}
+	expiry, ok := gaugeValue(t, reg, "probe_tls_cert_expiry_timestamp_seconds", map[string]string{"target": "tls"})
+	if !ok || expiry < float64(time.Now().Unix()) {
+		t.Errorf("probe_tls_cert_expiry_timestamp_seconds{target=tls} = %v, want a future timestamp", expiry)
+	}
+}
+
+// TestProbeFailureDropsStaleSeries 目标从健康变为不可达后，不应继续暴露上一次成功时的阶段耗时、证书和匹配结果
+func TestProbeFailureDropsStaleSeries(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ready"))
+	}))
+	c, reg := newProbeCollector(t, []collector.ProbeTargetConfig{
+		{Name: "api", Type: "http", Target: srv.URL, BodyRegex: "ready", InsecureSkipVerify: true},
+	})
+	target := map[string]string{"target": "api"}
+	phase := func(name string) (float64, bool) {
+		return gaugeValue(t, reg, "probe_phase_duration_seconds", map[string]string{"target": "api", "phase": name})
+	}
+
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	for _, name := range []string{"connect", "tls", "first_byte"} {
+		if _, ok := phase(name); !ok {
+			t.Errorf("missing %s phase while target healthy", name)
+		}
+	}
+	// first_byte 从连接就绪开始计时，不包含 TLS 握手
+	tlsDur, _ := phase("tls")
+	firstByte, _ := phase("first_byte")
+	total, _ := gaugeValue(t, reg, "probe_duration_seconds", target)
+	if tlsDur+firstByte > total {
+		t.Errorf("tls %v + first_byte %v exceeds total %v, first_byte should start after the handshake", tlsDur, firstByte, total)
+	}
+	if _, ok := gaugeValue(t, reg, "probe_tls_cert_expiry_timestamp_seconds", target); !ok {
+		t.Error("missing cert expiry while target healthy")
+	}
+	if v, ok := gaugeValue(t, reg, "probe_http_body_match", target); !ok || v != 1 {
+		t.Errorf("probe_http_body_match = %v (found %v), want 1", v, ok)
+	}
+
+	srv.Close()
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if v, _ := gaugeValue(t, reg, "probe_success", target); v != 0 {
+		t.Errorf("probe_success = %v after server closed, want 0", v)
+	}
+	for _, name := range []string{"tls", "first_byte"} {
+		if v, ok := phase(name); ok {
+			t.Errorf("stale %s phase %v still exported after failure", name, v)
+		}
+	}
+	if _, ok := gaugeValue(t, reg, "probe_tls_cert_expiry_timestamp_seconds", target); ok {
+		t.Error("stale cert expiry still exported after failure")
+	}
+	if _, ok := gaugeValue(t, reg, "probe_http_body_match", target); ok {
+		t.Error("stale body match still exported after failure")
+	}
+}
+
+func TestProbeTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	open := ln.Addr().String()
+
+	// 关闭后的端口用于模拟不可达目标
+	closedLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	closed := closedLn.Addr().String()
+	_ = closedLn.Close()
+	defer ln.Close()
+
+	tlsSrv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer tlsSrv.Close()
+
+	c, reg := newProbeCollector(t, []collector.ProbeTargetConfig{
+		{Name: "open", Type: "tcp", Target: open},
+		{Name: "closed", Type: "tcp", Target: closed},
+		{Name: "tls", Type: "tcp", Target: tlsSrv.Listener.Addr().String(), TLS: true, InsecureSkipVerify: true},
+	})
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	for target, want := range map[string]float64{"open": 1, "closed": 0, "tls": 1} {
+		if v, _ := gaugeValue(t, reg, "probe_success", map[string]string{"target": target, "type": "tcp"}); v != want {
+			t.Errorf("probe_success{target=%q} = %v, want %v", target, v, want)
+		}
+	}
+	if _, ok := gaugeValue(t, reg, "probe_phase_duration_seconds", map[string]string{"target": "tls", "phase": "tls"}); !ok {
+		t.Errorf("missing tls phase for target tls")
+	}
+	if _, ok := gaugeValue(t, reg, "probe_tls_cert_expiry_timestamp_seconds", map[string]string{"target": "tls"}); !ok {
+		t.Errorf("missing cert expiry for target tls")
+	}
+}
+
+func TestProbeDNS(t *testing.T) {
+	c, reg := newProbeCollector(t, []collector.ProbeTargetConfig{
+		{Name: "localhost", Type: "dns", Target: "localhost", QueryType: "A"},
+		{Name: "invalid", Type: "dns", Target: "does-not-exist.invalid", QueryType: "A"},
+	})
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	if v, _ := gaugeValue(t, reg, "probe_success", map[string]string{"target": "localhost"}); v != 1 {
+		t.Errorf("probe_success{target=localhost} = %v, want 1", v)
+	}
+	if v, _ := gaugeValue(t, reg, "probe_dns_answers", map[string]string{"target": "localhost"}); v < 1 {
+		t.Errorf("probe_dns_answers{target=localhost} = %v, want >= 1", v)
+	}
+	if v, _ := gaugeValue(t, reg, "probe_success", map[string]string{"target": "invalid"}); v != 0 {
+		t.Errorf("probe_success{target=invalid} = %v, want 0", v)
+	}
+}
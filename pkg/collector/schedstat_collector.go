@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+	"github.com/agent-collector/pkg/registers"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"time"
+)
+
+// procSchedstatPath 调度统计文件（内核需开启 CONFIG_SCHEDSTATS）
+func procSchedstatPath() string { return procfs.ProcPath("schedstat") }
+
+// SchedStat /proc/schedstat 中单个 CPU 的运行队列统计
+// cpu 行格式（version 15+）："cpuN yld_count 0 sched_count sched_goidle ttwu_count ttwu_local running_ns waiting_ns timeslices"
+type SchedStat struct {
+	RunningNanoseconds uint64 // 任务在该 CPU 上的累计运行时间
+	WaitingNanoseconds uint64 // 任务在该 CPU 运行队列上的累计等待时间
+	Timeslices         uint64 // 在该 CPU 上运行过的时间片数
+}
+
+// parseSchedstat 逐行解析 cpu 行（domain 行跳过），id 为 "cpuN"，只在回调内有效
+func parseSchedstat(data []byte, fn func(id []byte, stat SchedStat)) error {
+	for len(data) > 0 {
+		var line []byte
+		line, data = procfs.NextLine(data)
+		if !procfs.HasPrefix(line, "cpu") {
+			continue
+		}
+		id, rest := procfs.NextField(line)
+		var values [9]uint64
+		n := 0
+		for n < len(values) {
+			var field []byte
+			field, rest = procfs.NextField(rest)
+			if len(field) == 0 {
+				break
+			}
+			v, ok := procfs.ParseUint(field)
+			if !ok {
+				return fmt.Errorf("invalid schedstat field %q in %s", field, id)
+			}
+			values[n] = v
+			n++
+		}
+		if n < len(values) {
+			return fmt.Errorf("unexpected schedstat format for %s: %d fields", id, n)
+		}
+		fn(id, SchedStat{RunningNanoseconds: values[6], WaitingNanoseconds: values[7], Timeslices: values[8]})
+	}
+	return nil
+}
+
+// SchedstatConfig 调度统计采集配置（collectors.schedstat，需要内核开启 CONFIG_SCHEDSTATS），目前没有额外配置项
+type SchedstatConfig struct{}
+
+func init() {
+	registers.RegisterFactory(registers.Factory{
+		Name:        "schedstat",
+		Description: "run queue wait time from /proc/schedstat (/proc/schedstat 调度器运行队列等待时间)",
+		NewConfig:   func() any { return &SchedstatConfig{} },
+		New: func(cfg any, deps registers.Deps) (registers.Collector, error) {
+			return NewSchedstatCollector(cfg.(*SchedstatConfig), deps.MetricFactory, deps.FS), nil
+		},
+	})
+}
+
+// SchedstatCollector 调度统计采集器（实现Collector接口）
+// 输出每个 CPU 的运行时间、运行队列等待时间和时间片数，用于计算平均调度延迟（noisy neighbor 的关键信号）
+type SchedstatCollector struct {
+	name            string
+	cfg             *SchedstatConfig
+	metrics         metrics.SchedstatCollectorMetrics
+	collectErrors   *prometheus.CounterVec
+	collectDuration *prometheus.HistogramVec
+	fs              procfs.FS
+
+	last   map[string]SchedStat // 上一次采样值，计数器只累加增量
+	ids    map[string]string    // 原始标识 -> 标签值，避免每个周期重复分配
+	series *metrics.SeriesScope // CPU 下线后删除过期序列
+	buf    bytes.Buffer         // 没有快照时读取文件的缓冲区
+}
+
+// NewSchedstatCollector 创建调度统计采集器
+func NewSchedstatCollector(cfg *SchedstatConfig, metricFactory metrics.MetricFactory, fsys procfs.FS) *SchedstatCollector {
+	return &SchedstatCollector{
+		name: "schedstat-collector",
+		cfg:  cfg,
+		metrics: metrics.SchedstatCollectorMetrics{
+			RunningSeconds: metricFactory.NewSchedstatRunningSecondsTotal(),
+			WaitingSeconds: metricFactory.NewSchedstatWaitingSecondsTotal(),
+			Timeslices:     metricFactory.NewSchedstatTimeslicesTotal(),
+		},
+		collectErrors:   metricFactory.NewAgentCollectErrorsTotal(),
+		collectDuration: metricFactory.NewAgentCollectDurationSeconds(),
+		fs:              fsys,
+		last:            make(map[string]SchedStat),
+		ids:             make(map[string]string),
+		series:          metricFactory.SeriesTracker().Scope("schedstat-collector"),
+	}
+}
+
+// Name 返回采集器名称
+func (c *SchedstatCollector) Name() string { return c.name }
+
+// Init 检查内核是否提供 /proc/schedstat
+func (c *SchedstatCollector) Init() error {
+	if _, err := c.fs.Stat(procSchedstatPath()); err != nil {
+		logger.Error("scheduler statistics not available (kernel built without CONFIG_SCHEDSTATS?)", zap.String("path", procSchedstatPath()), zap.Error(err))
+		return fmt.Errorf("stat %s: %w", procSchedstatPath(), err)
+	}
+	return nil
+}
+
+// Collect 读取 /proc/schedstat 并按增量更新计数器
+func (c *SchedstatCollector) Collect(ctx context.Context) error {
+	start := time.Now()
+	defer func() {
+		c.collectDuration.WithLabelValues(c.name).Observe(time.Since(start).Seconds())
+	}()
+
+	data, err := procfs.ReadFile(ctx, c.fs, procSchedstatPath(), &c.buf)
+	if err != nil {
+		c.collectErrors.WithLabelValues(c.name).Inc()
+		return fmt.Errorf("read %s: %w", procSchedstatPath(), err)
+	}
+
+	c.series.Begin()
+	err = parseSchedstat(data, func(raw []byte, stat SchedStat) {
+		id, ok := c.ids[string(raw)]
+		if !ok {
+			id = string(raw)
+			c.ids[id] = id
+		}
+		// 首次采集 last 为零值，直接加上内核累计值，之后只加增量
+		last := c.last[id]
+		addCounterDelta(c.metrics.RunningSeconds.WithLabelValues(id), last.RunningNanoseconds, stat.RunningNanoseconds, 1e-9)
+		addCounterDelta(c.metrics.WaitingSeconds.WithLabelValues(id), last.WaitingNanoseconds, stat.WaitingNanoseconds, 1e-9)
+		addCounterDelta(c.metrics.Timeslices.WithLabelValues(id), last.Timeslices, stat.Timeslices, 1)
+		c.series.Touch(c.metrics.RunningSeconds, id)
+		c.series.Touch(c.metrics.WaitingSeconds, id)
+		c.series.Touch(c.metrics.Timeslices, id)
+		c.last[id] = stat
+	})
+	if err != nil {
+		// 解析中途失败时后面的 CPU 没有被 Touch，不能当作下线处理
+		c.collectErrors.WithLabelValues(c.name).Inc()
+		return err
+	}
+	for _, s := range c.series.End() {
+		delete(c.last, s.Labels[0])
+	}
+	logger.Debug("collected scheduler statistics", zap.String("name", c.name), zap.Int("cpus", len(c.last)))
+	return nil
+}
+
+// Close 无需释放资源
+func (c *SchedstatCollector) Close() error { return nil }
+
+// addCounterDelta 按增量累加计数器（乘以 scale 换算单位），内核值回退时忽略本次增量
+func addCounterDelta(counter prometheus.Counter, last, cur uint64, scale float64) {
+	if cur > last {
+		counter.Add(float64(cur-last) * scale)
+	}
+}
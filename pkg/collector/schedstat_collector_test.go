@@ -0,0 +1,123 @@
+package collector_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/agent-collector/pkg/collector"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// schedstatV15 version 15 格式：cpu 行之后跟随该 CPU 的 domain 行
+const schedstatV15 = `version 15
+timestamp 4295032710
+cpu0 0 0 184 41 97 73 2500000000 1200000000 300
+domain0 00000000,00000003 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0
+cpu1 0 0 90 20 50 30 4000000000 800000000 150
+domain0 00000000,00000003 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0
+`
+
+func newSchedstatCollector(t *testing.T, fsys procfs.FS) (*collector.SchedstatCollector, *prometheus.Registry) {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	factory := metrics.NewMetricFactory(metrics.NewPromRegistry(reg))
+	factory.SeriesTracker().SetStaleCycles(1) // 一个周期未出现即视为下线，便于暴露误删
+	c := collector.NewSchedstatCollector(&collector.SchedstatConfig{}, *factory, fsys)
+	if err := c.Init(); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	return c, reg
+}
+
+func TestSchedstatCollector(t *testing.T) {
+	fsys := procfs.MapFS{"/proc/schedstat": {Data: []byte(schedstatV15)}}
+	c, reg := newSchedstatCollector(t, fsys)
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	// version 15 字段顺序：第 7/8/9 个数值为 running_ns/waiting_ns/timeslices，domain 行被跳过
+	cases := []struct {
+		metric string
+		cpu    string
+		want   float64
+	}{
+		{"schedstat_running_seconds_total", "cpu0", 2.5},
+		{"schedstat_waiting_seconds_total", "cpu0", 1.2},
+		{"schedstat_timeslices_total", "cpu0", 300},
+		{"schedstat_running_seconds_total", "cpu1", 4},
+		{"schedstat_waiting_seconds_total", "cpu1", 0.8},
+		{"schedstat_timeslices_total", "cpu1", 150},
+	}
+	for _, tc := range cases {
+		if got, ok := gaugeValue(t, reg, tc.metric, map[string]string{"cpu": tc.cpu}); !ok || !approxEqual(got, tc.want) {
+			t.Errorf("%s{cpu=%q} = %v (found %v), want %v", tc.metric, tc.cpu, got, ok, tc.want)
+		}
+	}
+	if _, ok := gaugeValue(t, reg, "schedstat_running_seconds_total", map[string]string{"cpu": "domain0"}); ok {
+		t.Error("domain line exported as a cpu")
+	}
+}
+
+func TestSchedstatCollectorParseErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"short cpu line", "version 15\ncpu0 0 0 184 41 97 73 2500000000\n", "cpu0: 7 fields"},
+		{"non numeric field", "version 15\ncpu0 0 0 184 41 97 73 2500000000 x 300\n", `invalid schedstat field "x"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := newSchedstatCollector(t, procfs.MapFS{"/proc/schedstat": {Data: []byte(tc.data)}})
+			if err := c.Collect(context.Background()); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("collect error = %v, want containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestSchedstatPartialParseKeepsSeries 解析中途失败时，失败行之后的 CPU 不能被当作下线删除
+func TestSchedstatPartialParseKeepsSeries(t *testing.T) {
+	fsys := procfs.MapFS{"/proc/schedstat": {Data: []byte(schedstatV15)}}
+	c, reg := newSchedstatCollector(t, fsys)
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	fsys["/proc/schedstat"] = &fstest.MapFile{Data: []byte("version 15\n" +
+		"cpu0 0 0 200 45 100 75 3000000000 1300000000 320\n" +
+		"cpu1 0 0 95\n")}
+	if err := c.Collect(context.Background()); err == nil {
+		t.Fatal("collect succeeded on truncated cpu line")
+	}
+	if got, ok := gaugeValue(t, reg, "schedstat_running_seconds_total", map[string]string{"cpu": "cpu1"}); !ok || !approxEqual(got, 4) {
+		t.Fatalf("cpu1 running = %v (found %v) after failed parse, want 4 kept", got, ok)
+	}
+
+	// 下一轮恢复正常：cpu1 只累加增量，不会把内核累计值重复加一遍
+	fsys["/proc/schedstat"] = &fstest.MapFile{Data: []byte("version 15\n" +
+		"cpu0 0 0 200 45 100 75 3000000000 1300000000 320\n" +
+		"cpu1 0 0 95 21 52 31 4500000000 900000000 160\n")}
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if got, _ := gaugeValue(t, reg, "schedstat_running_seconds_total", map[string]string{"cpu": "cpu1"}); !approxEqual(got, 4.5) {
+		t.Errorf("cpu1 running = %v after recovery, want 4.5", got)
+	}
+
+	// 真正下线的 CPU 仍然会被删除
+	fsys["/proc/schedstat"] = &fstest.MapFile{Data: []byte("version 15\n" +
+		"cpu0 0 0 210 46 101 76 3100000000 1350000000 330\n")}
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if _, ok := gaugeValue(t, reg, "schedstat_running_seconds_total", map[string]string{"cpu": "cpu1"}); ok {
+		t.Error("offline cpu1 still exported")
+	}
+}
@@ -0,0 +1,23 @@
+#!/bin/sh
+# 在目标主机上采集 fixture_test.go 使用的 /proc、/sys 文件
+# 用法：capture.sh <目标目录>，如 capture.sh pkg/collector/testdata/fixtures/arm
+# 采集后执行 go test ./pkg/collector -run TestFixtureGolden -update 重新生成 golden 文件
+set -eu
+
+dest=${1:?usage: capture.sh <dest-dir>}
+rm -rf "$dest"
+mkdir -p "$dest/proc" "$dest/sys/devices/system"
+
+for f in stat cpuinfo loadavg schedstat; do
+	# schedstat 需要内核开启 CONFIG_SCHEDSTATS，不存在时跳过
+	if [ -r "/proc/$f" ]; then
+		cat "/proc/$f" >"$dest/proc/$f"
+	fi
+done
+
+# sysfs 中个别属性读取会返回 EIO（如 power/autosuspend_delay_ms），复制失败的文件删除而不是保留空文件
+cp -a /sys/devices/system/cpu "$dest/sys/devices/system/" 2>/dev/null || true
+find "$dest/sys" -type f -size 0 -name autosuspend_delay_ms -delete
+
+# cpuinfo 中的序列号等主机标识需要脱敏后再提交
+sed -i -e 's/^\(Serial[[:space:]]*:\).*/\1 0000000000000000/' "$dest/proc/cpuinfo"
@@ -0,0 +1,59 @@
+// testplugin 插件采集器测试使用的插件，通过命令行参数控制行为
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/agent-collector/pkg/plugin"
+)
+
+type testPlugin struct {
+	name       string
+	value      float64
+	exitAfter  int // 完成多少次采集后异常退出（0 为不退出）
+	undeclared bool
+	collects   int
+}
+
+func (p *testPlugin) Describe() plugin.Description {
+	return plugin.Description{
+		Name:    p.name,
+		Version: "0.0.1",
+		Metrics: []plugin.MetricInfo{{Name: "testplugin_value", Help: "Value given on the command line", Type: "gauge"}},
+	}
+}
+
+func (p *testPlugin) Collect(ctx context.Context) (string, error) {
+	if p.exitAfter > 0 && p.collects >= p.exitAfter {
+		fmt.Fprintln(os.Stderr, "crashing as requested")
+		os.Exit(3)
+	}
+	p.collects++
+	text := fmt.Sprintf("# TYPE testplugin_value gauge\ntestplugin_value{plugin=%q} %g\n", p.name, p.value)
+	if p.undeclared {
+		text += "# TYPE testplugin_undeclared gauge\ntestplugin_undeclared 1\n"
+	}
+	return text, nil
+}
+
+func main() {
+	p := &testPlugin{}
+	flag.StringVar(&p.name, "name", "test", "plugin name")
+	flag.Float64Var(&p.value, "value", 1, "value of testplugin_value")
+	flag.IntVar(&p.exitAfter, "exit-after", 0, "exit with status 3 on the collect request after this many collects")
+	flag.BoolVar(&p.undeclared, "undeclared", false, "also emit a metric that is not declared in describe")
+	marker := flag.String("shutdown-marker", "", "file created when a shutdown request is received")
+	flag.Parse()
+
+	fmt.Fprintln(os.Stderr, "started")
+	if err := plugin.Serve(context.Background(), p, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *marker != "" {
+		_ = os.WriteFile(*marker, []byte("shutdown\n"), 0o644)
+	}
+}
@@ -0,0 +1,270 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/registers"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TextfileConfig textfile 采集配置（collectors.textfile，合并外部程序生成的 *.prom 文件）
+type TextfileConfig struct {
+	Directories []string `yaml:"directories" mapstructure:"directories" comment:"*.prom 文件所在目录列表"`
+}
+
+// Validate 至少配置一个目录，且目录不能重复
+func (t *TextfileConfig) Validate() error {
+	if len(t.Directories) == 0 {
+		return fmt.Errorf("textfile.directories cannot be empty when textfile collector is enabled")
+	}
+	seen := map[string]bool{}
+	for _, dir := range t.Directories {
+		if strings.TrimSpace(dir) == "" {
+			return fmt.Errorf("textfile.directories cannot contain empty string")
+		}
+		if seen[dir] {
+			return fmt.Errorf("textfile.directories duplicated entry: %q", dir)
+		}
+		seen[dir] = true
+	}
+	return nil
+}
+
+func init() {
+	registers.RegisterFactory(registers.Factory{
+		Name:        "textfile",
+		Description: "*.prom files written by other programs (外部程序生成的 *.prom 文件)",
+		NewConfig:   func() any { return &TextfileConfig{} },
+		New: func(cfg any, deps registers.Deps) (registers.Collector, error) {
+			return NewTextfileCollector(cfg.(*TextfileConfig), deps.MetricFactory), nil
+		},
+	})
+}
+
+// TextfileCollector textfile采集器（实现Collector接口）
+// 读取配置目录中的 *.prom 文件并合并到 /metrics 输出，单个文件出错只影响该文件
+type TextfileCollector struct {
+	name            string
+	cfg             *TextfileConfig
+	metrics         metrics.TextfileCollectorMetrics
+	collectErrors   *prometheus.CounterVec
+	collectDuration *prometheus.HistogramVec
+}
+
+// NewTextfileCollector 创建textfile采集器
+func NewTextfileCollector(cfg *TextfileConfig, metricFactory metrics.MetricFactory) *TextfileCollector {
+	return &TextfileCollector{
+		name: "textfile-collector",
+		cfg:  cfg,
+		metrics: metrics.TextfileCollectorMetrics{
+			ScrapeError: metricFactory.NewTextfileScrapeError(),
+			Mtime:       metricFactory.NewTextfileMtimeSeconds(),
+			Families:    metricFactory.NewFamilyCollector(),
+		},
+		collectErrors:   metricFactory.NewAgentCollectErrorsTotal(),
+		collectDuration: metricFactory.NewAgentCollectDurationSeconds(),
+	}
+}
+
+// Name 返回采集器名称
+func (c *TextfileCollector) Name() string { return c.name }
+
+// Init 目录不存在只告警，cron 任务可能稍后才创建目录
+func (c *TextfileCollector) Init() error {
+	for _, dir := range c.cfg.Directories {
+		if _, err := os.Stat(dir); err != nil {
+			logger.Warn("textfile directory not accessible", zap.String("dir", dir), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Collect 扫描所有目录下的 *.prom 文件，解析后整体替换暴露的指标族
+func (c *TextfileCollector) Collect(ctx context.Context) error {
+	start := time.Now()
+	defer func() {
+		c.collectDuration.WithLabelValues(c.name).Observe(time.Since(start).Seconds())
+	}()
+
+	merged := newFamilyMerger()
+	errorFiles := make(map[string]float64)
+	mtimes := make(map[string]float64)
+
+	for _, dir := range c.cfg.Directories {
+		files, err := listPromFiles(dir)
+		if err != nil {
+			logger.Warn("failed to read textfile directory", zap.String("dir", dir), zap.Error(err))
+			c.collectErrors.WithLabelValues(c.name).Inc()
+			continue
+		}
+		for _, path := range files {
+			mtime, families, err := parseTextfile(path)
+			if err == nil {
+				err = merged.add(families)
+			}
+			if err != nil {
+				logger.Warn("failed to read textfile", zap.String("file", path), zap.Error(err))
+				errorFiles[path] = 1
+				continue
+			}
+			errorFiles[path] = 0
+			mtimes[path] = float64(mtime.UnixNano()) / 1e9
+		}
+	}
+
+	c.metrics.Families.Update(merged.families())
+
+	// 文件被删除后对应序列需要消失，先清空再写入本轮结果
+	c.metrics.ScrapeError.Reset()
+	c.metrics.Mtime.Reset()
+	for path, v := range errorFiles {
+		c.metrics.ScrapeError.WithLabelValues(path).Set(v)
+	}
+	for path, v := range mtimes {
+		c.metrics.Mtime.WithLabelValues(path).Set(v)
+	}
+
+	logger.Debug("collected textfiles", zap.String("name", c.name), zap.Int("files", len(errorFiles)))
+	return nil
+}
+
+// Close 无需释放资源
+func (c *TextfileCollector) Close() error { return nil }
+
+// listPromFiles 返回目录中所有 *.prom 普通文件（按名称排序，保证合并顺序稳定）
+func listPromFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".prom") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// parseTextfile 解析单个 Prometheus 文本格式文件，返回文件 mtime 和指标族
+func parseTextfile(path string) (time.Time, map[string]*dto.MetricFamily, error) {
+	open, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	defer open.Close()
+
+	stat, err := open.Stat()
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	families, err := ParsePromText(open)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return stat.ModTime(), families, nil
+}
+
+// ParsePromText 使用 expfmt 解析 Prometheus 文本格式
+// 带时间戳的样本会被拒绝：agent 按采集时刻输出，不支持透传外部时间戳
+func ParsePromText(in io.Reader) (map[string]*dto.MetricFamily, error) {
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(in)
+	if err != nil {
+		return nil, fmt.Errorf("parse text format: %w", err)
+	}
+	for name, mf := range families {
+		for _, m := range mf.GetMetric() {
+			if m.TimestampMs != nil {
+				return nil, fmt.Errorf("metric %s has explicit timestamp, which is not supported", name)
+			}
+		}
+	}
+	return families, nil
+}
+
+// familyMerger 合并多个来源的指标族，同名指标族类型必须一致且标签组合不能重复
+type familyMerger struct {
+	byName map[string]*dto.MetricFamily
+	seen   map[string]struct{}
+}
+
+func newFamilyMerger() *familyMerger {
+	return &familyMerger{
+		byName: make(map[string]*dto.MetricFamily),
+		seen:   make(map[string]struct{}),
+	}
+}
+
+// add 合并一个来源的全部指标族；任一冲突时整个来源被拒绝，不会部分合并
+func (fm *familyMerger) add(families map[string]*dto.MetricFamily) error {
+	keys := make(map[string]struct{})
+	for name, mf := range families {
+		if existing, ok := fm.byName[name]; ok && existing.GetType() != mf.GetType() {
+			return fmt.Errorf("metric %s type %s conflicts with previously read type %s", name, mf.GetType(), existing.GetType())
+		}
+		for _, m := range mf.GetMetric() {
+			key := seriesKey(name, m)
+			if _, dup := fm.seen[key]; dup {
+				return fmt.Errorf("metric %s has duplicate series %s", name, key)
+			}
+			// 同一来源内部也不能重复，否则 registry 在 Gather 时会报错
+			if _, dup := keys[key]; dup {
+				return fmt.Errorf("metric %s has duplicate series %s in the same source", name, key)
+			}
+			keys[key] = struct{}{}
+		}
+	}
+
+	for key := range keys {
+		fm.seen[key] = struct{}{}
+	}
+	for name, mf := range families {
+		if existing, ok := fm.byName[name]; ok {
+			existing.Metric = append(existing.Metric, mf.GetMetric()...)
+			continue
+		}
+		// 复制一份，避免后续合并修改调用方缓存的指标族
+		fm.byName[name] = &dto.MetricFamily{
+			Name:   mf.Name,
+			Help:   mf.Help,
+			Type:   mf.Type,
+			Unit:   mf.Unit,
+			Metric: append([]*dto.Metric(nil), mf.GetMetric()...),
+		}
+	}
+	return nil
+}
+
+// families 返回合并后的指标族列表
+func (fm *familyMerger) families() []*dto.MetricFamily {
+	out := make([]*dto.MetricFamily, 0, len(fm.byName))
+	for _, mf := range fm.byName {
+		out = append(out, mf)
+	}
+	return out
+}
+
+// seriesKey 生成 name{k="v",...} 形式的序列标识（标签按名称排序）
+func seriesKey(name string, m *dto.Metric) string {
+	pairs := make([]string, 0, len(m.GetLabel()))
+	for _, lp := range m.GetLabel() {
+		pairs = append(pairs, lp.GetName()+"="+fmt.Sprintf("%q", lp.GetValue()))
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,182 @@
+package collector_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/collector"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestParsePromText(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   string
+		want    map[string]int // 指标族名 -> 序列数
+		wantErr string
+	}{
+		{
+			name: "typed families",
+			input: `# HELP backup_last_success_seconds Last successful backup.
+# TYPE backup_last_success_seconds gauge
+backup_last_success_seconds{job="db"} 1.7e9
+backup_last_success_seconds{job="files"} 1.6e9
+# TYPE backup_runs_total counter
+backup_runs_total 12
+`,
+			want: map[string]int{"backup_last_success_seconds": 2, "backup_runs_total": 1},
+		},
+		{
+			name:  "untyped",
+			input: "raid_degraded 0\n",
+			want:  map[string]int{"raid_degraded": 1},
+		},
+		{
+			name:  "empty",
+			input: "",
+			want:  map[string]int{},
+		},
+		{
+			name:    "explicit timestamp",
+			input:   "raid_degraded 0 1700000000000\n",
+			wantErr: "explicit timestamp",
+		},
+		{
+			name:    "syntax error",
+			input:   "raid_degraded{disk=\"sda\" 0\n",
+			wantErr: "parse text format",
+		},
+		{
+			name:    "bad value",
+			input:   "raid_degraded zero\n",
+			wantErr: "parse text format",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			families, err := collector.ParsePromText(strings.NewReader(tc.input))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if len(families) != len(tc.want) {
+				t.Fatalf("got %d families, want %d", len(families), len(tc.want))
+			}
+			for name, n := range tc.want {
+				if got := len(families[name].GetMetric()); got != n {
+					t.Errorf("%s: %d series, want %d", name, got, n)
+				}
+			}
+		})
+	}
+}
+
+func writeProm(t *testing.T, dir, name, content string, mtime time.Time) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTextfileCollector(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	mtime := time.Unix(1760000000, 0)
+
+	backup := writeProm(t, dirA, "backup.prom", `# TYPE backup_ok gauge
+backup_ok{job="db"} 1
+`, mtime)
+	// 与 backup.prom 同名指标族、不同标签，合并后一起输出
+	backupFiles := writeProm(t, dirB, "backup-files.prom", `# TYPE backup_ok gauge
+backup_ok{job="files"} 0
+`, mtime.Add(time.Minute))
+	broken := writeProm(t, dirA, "broken.prom", "backup_ok{job=\"broken\" 1\n", mtime)
+	dupInFile := writeProm(t, dirA, "dup.prom", `raid_degraded{md="md0"} 0
+raid_degraded{md="md0"} 1
+`, mtime)
+	dupAcross := writeProm(t, dirB, "dup-across.prom", `# TYPE backup_ok gauge
+backup_ok{job="db"} 0
+`, mtime)
+	typeConflict := writeProm(t, dirB, "type.prom", `# TYPE backup_ok counter
+backup_ok{job="other"} 3
+`, mtime)
+	// 非 .prom 文件不读取
+	writeProm(t, dirA, "notes.txt", "garbage", mtime)
+
+	reg := prometheus.NewRegistry()
+	cfg := &collector.TextfileConfig{Directories: []string{dirA, dirB}}
+	c := collector.NewTextfileCollector(cfg, *metrics.NewMetricFactory(metrics.NewPromRegistry(reg)))
+	if err := c.Init(); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	for path, want := range map[string]float64{
+		backup: 0, backupFiles: 0,
+		broken: 1, dupInFile: 1, dupAcross: 1, typeConflict: 1,
+	} {
+		if got, ok := gaugeValue(t, reg, "textfile_scrape_error", map[string]string{"file": path}); !ok || got != want {
+			t.Errorf("textfile_scrape_error{file=%q} = %v (found %v), want %v", filepath.Base(path), got, ok, want)
+		}
+	}
+	if _, ok := gaugeValue(t, reg, "textfile_scrape_error", map[string]string{"file": filepath.Join(dirA, "notes.txt")}); ok {
+		t.Error("non .prom file was read")
+	}
+
+	for path, want := range map[string]time.Time{backup: mtime, backupFiles: mtime.Add(time.Minute)} {
+		if got, ok := gaugeValue(t, reg, "textfile_mtime_seconds", map[string]string{"file": path}); !ok || got != float64(want.Unix()) {
+			t.Errorf("textfile_mtime_seconds{file=%q} = %v (found %v), want %d", filepath.Base(path), got, ok, want.Unix())
+		}
+	}
+	if _, ok := gaugeValue(t, reg, "textfile_mtime_seconds", map[string]string{"file": broken}); ok {
+		t.Error("mtime exported for a file that failed to parse")
+	}
+
+	// 好文件的序列正常输出，坏文件的内容一条都不出现
+	for job, want := range map[string]float64{"db": 1, "files": 0} {
+		if got, ok := gaugeValue(t, reg, "backup_ok", map[string]string{"job": job}); !ok || got != want {
+			t.Errorf("backup_ok{job=%q} = %v (found %v), want %v", job, got, ok, want)
+		}
+	}
+	for _, job := range []string{"broken", "other"} {
+		if _, ok := gaugeValue(t, reg, "backup_ok", map[string]string{"job": job}); ok {
+			t.Errorf("backup_ok{job=%q} exported from a rejected file", job)
+		}
+	}
+	if _, ok := gaugeValue(t, reg, "raid_degraded", nil); ok {
+		t.Error("raid_degraded exported from a file with duplicate series")
+	}
+
+	// 文件删除后下一轮对应序列消失
+	if err := os.Remove(backupFiles); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if _, ok := gaugeValue(t, reg, "backup_ok", map[string]string{"job": "files"}); ok {
+		t.Error("series from removed file still exported")
+	}
+	if _, ok := gaugeValue(t, reg, "textfile_mtime_seconds", map[string]string{"file": backupFiles}); ok {
+		t.Error("mtime of removed file still exported")
+	}
+	if got, ok := gaugeValue(t, reg, "backup_ok", map[string]string{"job": "db"}); !ok || got != 1 {
+		t.Errorf("backup_ok{job=\"db\"} = %v (found %v) after second collect, want 1", got, ok)
+	}
+}
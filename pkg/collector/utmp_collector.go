@@ -0,0 +1,274 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+	"github.com/agent-collector/pkg/registers"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"net"
+	"strings"
+	"time"
+)
+
+// utmp 记录布局（glibc，Linux x86_64/aarch64 通用，固定 384 字节）
+const (
+	utmpRecordSize  = 384
+	utmpUserProcess = 7 // ut_type = USER_PROCESS 表示一个正常的登录会话
+
+	utmpOffType    = 0
+	utmpOffPid     = 4
+	utmpOffLine    = 8
+	utmpOffUser    = 44
+	utmpOffHost    = 76
+	utmpOffTvSec   = 340
+	utmpOffAddrV6  = 348
+	utmpLineSize   = 32
+	utmpUserSize   = 32
+	utmpHostSize   = 256
+	utmpAddrV6Size = 16
+)
+
+// UtmpSession 一条解析后的 USER_PROCESS 会话记录
+type UtmpSession struct {
+	User    string
+	Line    string
+	Host    string
+	Pid     int32
+	Addr    net.IP
+	Started time.Time
+}
+
+// sessionKey 会话唯一标识，用于识别新登录事件
+type sessionKey struct {
+	line string
+	pid  int32
+	sec  int64
+}
+
+// sessionLabels 会话统计维度
+type sessionLabels struct {
+	user        string
+	ttyType     string
+	remoteClass string
+}
+
+// SessionsConfig 登录会话采集配置（collectors.sessions，解析 utmp 文件）
+type SessionsConfig struct {
+	UtmpPath    string `yaml:"utmp_path" mapstructure:"utmp_path" comment:"utmp文件路径"`
+	LogSessions bool   `yaml:"log_sessions" mapstructure:"log_sessions" comment:"是否将新会话登录事件写入日志"`
+}
+
+// Validate utmp 路径不能为空
+func (s *SessionsConfig) Validate() error {
+	if strings.TrimSpace(s.UtmpPath) == "" {
+		return fmt.Errorf("sessions.utmp_path cannot be empty when sessions collector is enabled")
+	}
+	return nil
+}
+
+func init() {
+	registers.RegisterFactory(registers.Factory{
+		Name:        "sessions",
+		Description: "login sessions from utmp (utmp 登录会话)",
+		NewConfig:   func() any { return &SessionsConfig{UtmpPath: "/var/run/utmp"} },
+		New: func(cfg any, deps registers.Deps) (registers.Collector, error) {
+			return NewSessionCollector(cfg.(*SessionsConfig), deps.MetricFactory, deps.FS), nil
+		},
+	})
+}
+
+// SessionCollector 登录会话采集器（实现Collector接口）
+type SessionCollector struct {
+	name            string
+	cfg             *SessionsConfig
+	metrics         metrics.SessionCollectorMetrics
+	collectErrors   *prometheus.CounterVec
+	collectDuration *prometheus.HistogramVec
+	fs              procfs.FS
+
+	knownSessions map[sessionKey]struct{} // 上一轮已存在的会话，用于识别新会话
+	firstScan     bool                    // 首次扫描只记录已有会话，不当作新登录事件
+}
+
+// NewSessionCollector 创建登录会话采集器
+func NewSessionCollector(cfg *SessionsConfig, metricFactory metrics.MetricFactory, fsys procfs.FS) *SessionCollector {
+	return &SessionCollector{
+		name: "sessions-collector",
+		cfg:  cfg,
+		metrics: metrics.SessionCollectorMetrics{
+			Active: metricFactory.NewSessionsActive(),
+		},
+		collectErrors:   metricFactory.NewAgentCollectErrorsTotal(),
+		collectDuration: metricFactory.NewAgentCollectDurationSeconds(),
+		fs:              fsys,
+		knownSessions:   make(map[sessionKey]struct{}),
+		firstScan:       true,
+	}
+}
+
+// Name 返回采集器名称
+func (c *SessionCollector) Name() string { return c.name }
+
+// Init 检查 utmp 文件是否可读
+func (c *SessionCollector) Init() error {
+	open, err := c.fs.Open(c.utmpPath())
+	if err != nil {
+		logger.Error("failed to open utmp file", zap.String("path", c.utmpPath()), zap.Error(err))
+		return fmt.Errorf("open %s: %w", c.utmpPath(), err)
+	}
+	return open.Close()
+}
+
+// Collect 读取 utmp 并按用户/终端类型/来源分类统计会话数
+func (c *SessionCollector) Collect(ctx context.Context) error {
+	start := time.Now()
+	defer func() {
+		c.collectDuration.WithLabelValues(c.name).Observe(time.Since(start).Seconds())
+	}()
+
+	data, err := procfs.ReadFile(ctx, c.fs, c.utmpPath(), nil)
+	if err != nil {
+		c.collectErrors.WithLabelValues(c.name).Inc()
+		return fmt.Errorf("read %s: %w", c.utmpPath(), err)
+	}
+	sessions, err := ParseUtmp(data)
+	if err != nil {
+		// 文件尾部可能是正在写入的半条记录，已解析的完整记录仍然有效
+		logger.Warn("utmp file has trailing partial record", zap.String("path", c.utmpPath()), zap.Error(err))
+	}
+
+	counts := make(map[sessionLabels]float64)
+	current := make(map[sessionKey]struct{}, len(sessions))
+	for _, s := range sessions {
+		labels := sessionLabels{
+			user:        s.User,
+			ttyType:     classifyTTY(s.Line),
+			remoteClass: classifyRemote(s.Host, s.Addr),
+		}
+		counts[labels]++
+
+		key := sessionKey{line: s.Line, pid: s.Pid, sec: s.Started.Unix()}
+		current[key] = struct{}{}
+		if _, seen := c.knownSessions[key]; !seen && !c.firstScan && c.cfg.LogSessions {
+			logger.Info("login session started",
+				zap.String("user", s.User),
+				zap.String("tty", s.Line),
+				zap.String("tty_type", labels.ttyType),
+				zap.String("host", s.Host),
+				zap.String("remote_class", labels.remoteClass),
+				zap.Int32("pid", s.Pid),
+				zap.Time("started", s.Started))
+		}
+	}
+	c.knownSessions = current
+	c.firstScan = false
+
+	// 会话结束后对应的标签组合需要消失，先清空再写入本轮统计
+	c.metrics.Active.Reset()
+	for labels, n := range counts {
+		c.metrics.Active.WithLabelValues(labels.user, labels.ttyType, labels.remoteClass).Set(n)
+	}
+
+	logger.Debug("collected login sessions", zap.String("name", c.name), zap.Int("sessions", len(sessions)))
+	return nil
+}
+
+// Close 无需释放资源
+func (c *SessionCollector) Close() error { return nil }
+
+// utmpPath utmp 文件在宿主机根文件系统（path.rootfs）下的实际路径
+func (c *SessionCollector) utmpPath() string { return procfs.RootPath(c.cfg.UtmpPath) }
+
+// ParseUtmp 解析 utmp 二进制内容，仅返回 USER_PROCESS 类型的会话记录
+// 文件长度不是记录大小整数倍时，返回已解析的完整记录和错误
+func ParseUtmp(data []byte) ([]UtmpSession, error) {
+	var sessions []UtmpSession
+	n := len(data) / utmpRecordSize
+	for i := 0; i < n; i++ {
+		rec := data[i*utmpRecordSize : (i+1)*utmpRecordSize]
+		if int16(binary.LittleEndian.Uint16(rec[utmpOffType:])) != utmpUserProcess {
+			continue
+		}
+		s := UtmpSession{
+			User:    cString(rec[utmpOffUser : utmpOffUser+utmpUserSize]),
+			Line:    cString(rec[utmpOffLine : utmpOffLine+utmpLineSize]),
+			Host:    cString(rec[utmpOffHost : utmpOffHost+utmpHostSize]),
+			Pid:     int32(binary.LittleEndian.Uint32(rec[utmpOffPid:])),
+			Started: time.Unix(int64(int32(binary.LittleEndian.Uint32(rec[utmpOffTvSec:]))), 0),
+		}
+		addr := rec[utmpOffAddrV6 : utmpOffAddrV6+utmpAddrV6Size]
+		if !bytes.Equal(addr, make([]byte, utmpAddrV6Size)) {
+			// IPv4 地址只占用第一个 int32，其余为 0
+			if bytes.Equal(addr[4:], make([]byte, 12)) {
+				s.Addr = net.IPv4(addr[0], addr[1], addr[2], addr[3])
+			} else {
+				s.Addr = net.IP(append([]byte(nil), addr...))
+			}
+		}
+		sessions = append(sessions, s)
+	}
+	if len(data)%utmpRecordSize != 0 {
+		return sessions, fmt.Errorf("utmp size %d is not a multiple of record size %d", len(data), utmpRecordSize)
+	}
+	return sessions, nil
+}
+
+// cString 截取 C 字符串（以 \0 结尾）
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// classifyTTY 按终端名归类：pts/N 伪终端、ttyN 本地终端、console 控制台、:N 图形会话
+func classifyTTY(line string) string {
+	switch {
+	case strings.HasPrefix(line, "pts/"):
+		return "pts"
+	case strings.HasPrefix(line, "tty"):
+		return "tty"
+	case line == "console":
+		return "console"
+	case strings.HasPrefix(line, ":"):
+		return "display"
+	default:
+		return "other"
+	}
+}
+
+// classifyRemote 按来源主机归类，优先使用 ut_addr_v6，其次尝试把 ut_host 解析为 IP
+func classifyRemote(host string, addr net.IP) string {
+	if host == "" && addr == nil {
+		return "local"
+	}
+	// ":0" 这类 X display；"::1" 等 IPv6 地址同样以冒号开头，需要排除
+	if strings.HasPrefix(host, ":") && net.ParseIP(host) == nil {
+		return "display"
+	}
+	ip := addr
+	if ip == nil {
+		// ut_host 可能带有 X display 后缀，如 "10.0.0.1:0"
+		h := host
+		if i := strings.LastIndex(h, ":"); i > 0 && net.ParseIP(h) == nil {
+			h = h[:i]
+		}
+		ip = net.ParseIP(h)
+	}
+	switch {
+	case ip == nil:
+		return "hostname"
+	case ip.IsLoopback():
+		return "loopback"
+	case ip.IsPrivate() || ip.IsLinkLocalUnicast():
+		return "private"
+	default:
+		return "public"
+	}
+}
@@ -0,0 +1,166 @@
+package collector_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/collector"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// utmp ut_type 取值（utmp.h）
+const (
+	utLoginProcess = 6
+	utUserProcess  = 7
+	utDeadProcess  = 8
+)
+
+// utmpRecord glibc struct utmp 的内存布局，小端序写出后固定 384 字节
+type utmpRecord struct {
+	Type    int16
+	_       [2]byte
+	Pid     int32
+	Line    [32]byte
+	ID      [4]byte
+	User    [32]byte
+	Host    [256]byte
+	Exit    [2]int16
+	Session int32
+	TvSec   int32
+	TvUsec  int32
+	AddrV6  [16]byte
+	_       [20]byte
+}
+
+type utmpEntry struct {
+	typ  int16
+	pid  int32
+	line string
+	user string
+	host string
+	addr net.IP
+	sec  int32
+}
+
+func encodeUtmp(t *testing.T, entries ...utmpEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, e := range entries {
+		rec := utmpRecord{Type: e.typ, Pid: e.pid, TvSec: e.sec}
+		copy(rec.Line[:], e.line)
+		copy(rec.User[:], e.user)
+		copy(rec.Host[:], e.host)
+		if v4 := e.addr.To4(); v4 != nil {
+			copy(rec.AddrV6[:4], v4) // IPv4 只占 ut_addr_v6[0]
+		} else if e.addr != nil {
+			copy(rec.AddrV6[:], e.addr.To16())
+		}
+		if err := binary.Write(&buf, binary.LittleEndian, &rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if buf.Len() != len(entries)*384 {
+		t.Fatalf("encoded %d bytes for %d records, want 384 per record", buf.Len(), len(entries))
+	}
+	return buf.Bytes()
+}
+
+func TestParseUtmp(t *testing.T) {
+	alice := utmpEntry{typ: utUserProcess, pid: 1201, line: "pts/0", user: "alice", host: "10.0.0.5", addr: net.ParseIP("10.0.0.5"), sec: 1760745600}
+	bob := utmpEntry{typ: utUserProcess, pid: 1302, line: "tty1", user: "bob", sec: 1760745700}
+	carol := utmpEntry{typ: utUserProcess, pid: 1403, line: "pts/1", user: "carol", host: "2001:db8::7", addr: net.ParseIP("2001:db8::7"), sec: 1760745800}
+	dead := utmpEntry{typ: utDeadProcess, pid: 999, line: "pts/3", user: "mallory", host: "10.0.0.9", addr: net.ParseIP("10.0.0.9")}
+	getty := utmpEntry{typ: utLoginProcess, pid: 800, line: "tty2", user: "LOGIN"}
+
+	cases := []struct {
+		name    string
+		data    []byte
+		want    []utmpEntry
+		wantErr bool
+	}{
+		{name: "empty", data: nil},
+		{name: "ipv4 pts session", data: encodeUtmp(t, alice), want: []utmpEntry{alice}},
+		{name: "ipv6 address", data: encodeUtmp(t, carol), want: []utmpEntry{carol}},
+		{name: "local tty without address", data: encodeUtmp(t, bob), want: []utmpEntry{bob}},
+		{name: "dead and login process skipped", data: encodeUtmp(t, dead, alice, getty, bob), want: []utmpEntry{alice, bob}},
+		{
+			name:    "truncated trailing record",
+			data:    append(encodeUtmp(t, alice, carol), encodeUtmp(t, bob)[:100]...),
+			want:    []utmpEntry{alice, carol},
+			wantErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sessions, err := collector.ParseUtmp(tc.data)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if len(sessions) != len(tc.want) {
+				t.Fatalf("got %d sessions, want %d: %+v", len(sessions), len(tc.want), sessions)
+			}
+			for i, w := range tc.want {
+				s := sessions[i]
+				if s.User != w.user || s.Line != w.line || s.Host != w.host || s.Pid != w.pid {
+					t.Errorf("session %d = %+v, want %+v", i, s, w)
+				}
+				if !s.Started.Equal(time.Unix(int64(w.sec), 0)) {
+					t.Errorf("session %d started %v, want %v", i, s.Started, time.Unix(int64(w.sec), 0))
+				}
+				if (s.Addr == nil) != (w.addr == nil) || (w.addr != nil && !s.Addr.Equal(w.addr)) {
+					t.Errorf("session %d addr = %v, want %v", i, s.Addr, w.addr)
+				}
+			}
+		})
+	}
+}
+
+// TestSessionCollectorClassify 经 Collect 验证终端类型和来源分类（classifyTTY/classifyRemote）
+func TestSessionCollectorClassify(t *testing.T) {
+	cases := []struct {
+		name        string
+		entry       utmpEntry
+		ttyType     string
+		remoteClass string
+	}{
+		{"pts ipv4 private", utmpEntry{line: "pts/0", host: "10.0.0.5", addr: net.ParseIP("10.0.0.5")}, "pts", "private"},
+		{"pts ipv6 public", utmpEntry{line: "pts/1", host: "2001:db8::7", addr: net.ParseIP("2001:db8::7")}, "pts", "public"},
+		{"pts ipv6 loopback", utmpEntry{line: "pts/2", host: "::1", addr: net.IPv6loopback}, "pts", "loopback"},
+		{"tty local", utmpEntry{line: "tty1"}, "tty", "local"},
+		{"empty line", utmpEntry{line: "", host: "203.0.113.7"}, "other", "public"},
+		{"console", utmpEntry{line: "console"}, "console", "local"},
+		{"x display", utmpEntry{line: ":0", host: ":0"}, "display", "display"},
+		{"host with display suffix", utmpEntry{line: "pts/4", host: "192.168.1.20:0"}, "pts", "private"},
+		{"hostname only", utmpEntry{line: "pts/5", host: "jump.example.com"}, "pts", "hostname"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.entry.typ, tc.entry.user, tc.entry.pid = utUserProcess, "alice", 1000
+			dead := utmpEntry{typ: utDeadProcess, line: tc.entry.line, user: "mallory", host: tc.entry.host, addr: tc.entry.addr}
+			fsys := procfs.MapFS{"/var/run/utmp": {Data: encodeUtmp(t, tc.entry, dead)}}
+
+			reg := prometheus.NewRegistry()
+			c := collector.NewSessionCollector(&collector.SessionsConfig{UtmpPath: "/var/run/utmp"},
+				*metrics.NewMetricFactory(metrics.NewPromRegistry(reg)), fsys)
+			if err := c.Init(); err != nil {
+				t.Fatalf("init: %v", err)
+			}
+			if err := c.Collect(context.Background()); err != nil {
+				t.Fatalf("collect: %v", err)
+			}
+			labels := map[string]string{"user": "alice", "tty_type": tc.ttyType, "remote_class": tc.remoteClass}
+			if got, ok := gaugeValue(t, reg, "sessions_active", labels); !ok || got != 1 {
+				t.Errorf("sessions_active%v = %v (found %v), want 1", labels, got, ok)
+			}
+			if _, ok := gaugeValue(t, reg, "sessions_active", map[string]string{"user": "mallory"}); ok {
+				t.Error("DEAD_PROCESS record counted as active session")
+			}
+		})
+	}
+}
@@ -21,6 +21,14 @@ type Config struct {
 	Server  ServerConfig  `yaml:"server" mapstructure:"server" comment:"HTTP服务配置"` // 简化yaml键名（原 server_config → server，更简洁）
 	Monitor MonitorConfig `yaml:"metrics" mapstructure:"metrics" comment:"监控采集配置"` // 简化yaml键名（原 monitor_config → metrics）
 	Log     ZapLogConfig  `yaml:"logs" mapstructure:"logs" comment:"日志配置"`         // 简化yaml键名（原 logs_config → log）
+	Path    PathConfig    `yaml:"path" mapstructure:"path" comment:"宿主机文件系统挂载点"`
+}
+
+// PathConfig 宿主机 procfs/sysfs/rootfs 挂载点（容器内运行时指向挂载进来的宿主机目录）
+type PathConfig struct {
+	ProcFS string `yaml:"procfs" mapstructure:"procfs" env:"PATH_PROCFS" validate:"required" comment:"procfs挂载点" default:"/proc"`
+	SysFS  string `yaml:"sysfs" mapstructure:"sysfs" env:"PATH_SYSFS" validate:"required" comment:"sysfs挂载点" default:"/sys"`
+	RootFS string `yaml:"rootfs" mapstructure:"rootfs" env:"PATH_ROOTFS" validate:"required" comment:"宿主机根文件系统挂载点" default:"/"`
 }
 
 // ServerConfig HTTP服务配置（超时统一为time.Duration，支持"30s"解析）
@@ -29,44 +37,44 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `yaml:"read_timeout" mapstructure:"read_timeout" env:"HTTP_READ_TIMEOUT" validate:"required,gt=0" comment:"读取超时时间（如30s）"`
 	WriteTimeout time.Duration `yaml:"write_timeout" mapstructure:"write_timeout" env:"HTTP_WRITE_TIMEOUT" validate:"required,gt=0" comment:"写入超时时间（如30s）"`
 	IdleTimeout  time.Duration `yaml:"idle_timeout" mapstructure:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" validate:"required,gt=0" comment:"空闲连接超时时间（如60s）"`
+	APIToken     string        `yaml:"api_token" mapstructure:"api_token" json:"-" env:"HTTP_API_TOKEN" comment:"管理接口（/api/v1/collectors 的修改操作）的 Bearer Token，为空时禁止修改"`
 }
 
 // MonitorConfig 监控采集全局配置
 type MonitorConfig struct {
-	Interval   time.Duration   `yaml:"interval" mapstructure:"interval" env:"MONITOR_INTERVAL" validate:"required,gt=0" comment:"监控采集间隔（如10s）" default:"10s"`
-	Collectors CollectorConfig `yaml:"collectors" mapstructure:"collectors" comment:"各类数据源采集器配置"` // 键名改为collectors（复数更合理）
-}
-
-// CollectorConfig 多数据源采集器配置（简化字段名，避免冗余）
-type CollectorConfig struct {
-	Proc      ProcDataSourceConfig   `yaml:"proc" mapstructure:"proc" comment:"Linux /proc 数据源（CPU/内存等）"`                               // 原 enable_proc_data_source → proc（语义更清晰）
-	Sys       SysDataSourceConfig    `yaml:"sys" mapstructure:"sys" comment:"Linux /sys 数据源（磁盘/网络等）"`                                   // 原 enable_sys_data_source → sys
-	Cgroup    CgroupDataSourceConfig `yaml:"cgroup" mapstructure:"cgroup" comment:"Cgroup v1/v2 数据源（容器资源限制）"`                           // 原 enable_cgroup_data_source → cgroup
-	Container ContainerRuntimeConfig `yaml:"container_runtime" mapstructure:"container_runtime" comment:"容器运行时API（Docker/containerd等）"` // 简化结构体名
-}
-
-// ProcDataSourceConfig /proc 数据源配置（去掉冗余Enable前缀）
-type ProcDataSourceConfig struct {
-	Enable          bool          `yaml:"enable" mapstructure:"enable" env:"COLLECTOR_PROC_ENABLE" comment:"是否启用/proc数据源" default:"false"`
-	CollectPerCore  bool          `yaml:"collect_per_core" mapstructure:"collect_per_core" env:"COLLECTOR_PROC_PER_CORE" comment:"是否按每核心采集CPU指标" default:"false"`
-	LoadSampleCycle time.Duration `yaml:"load_sample_cycle" mapstructure:"load_sample_cycle" default:"1s"` // 负载采样周期
+	Interval     time.Duration             `yaml:"interval" mapstructure:"interval" env:"MONITOR_INTERVAL" validate:"required,gt=0" comment:"监控采集间隔（如10s）" default:"10s"`
+	Mode         string                    `yaml:"mode" mapstructure:"mode" env:"MONITOR_MODE" validate:"omitempty,oneof=push scrape" comment:"采集模式：push（按 interval 定时采集）/scrape（收到 scrape 请求时采集）" default:"push"`
+	ScrapeMinAge time.Duration             `yaml:"scrape_min_age" mapstructure:"scrape_min_age" env:"MONITOR_SCRAPE_MIN_AGE" comment:"scrape 模式下数据的最短复用时间，期间的其他 scrape 直接返回缓存结果" default:"1s"`
+	StaleCycles  int                       `yaml:"stale_cycles" mapstructure:"stale_cycles" env:"MONITOR_STALE_CYCLES" validate:"gte=0" comment:"序列连续多少个采集周期未更新后删除（0使用默认值3）" default:"3"`
+	Workers      int                       `yaml:"workers" mapstructure:"workers" env:"MONITOR_WORKERS" validate:"gte=0" comment:"同时运行的采集器数量上限（1为串行，0使用默认值4）" default:"4"`
+	Align        bool                      `yaml:"align" mapstructure:"align" env:"MONITOR_ALIGN" comment:"按采集间隔对齐墙上时钟（如 10s 间隔在每分钟的 :00 :10 :20 ... 采集），不同主机的数据点时间一致" default:"false"`
+	Jitter       time.Duration             `yaml:"jitter" mapstructure:"jitter" env:"MONITOR_JITTER" validate:"gte=0" comment:"按 machine-id 和采集器名称计算的固定偏移上限（不超过采集间隔），错开大量主机的采集时间，0 为不偏移" default:"0s"`
+	Collectors   map[string]map[string]any `yaml:"collectors" mapstructure:"collectors" comment:"按采集器名称配置（collectors.<name>.enable 及该采集器自己的配置项）"`
+	Schedules    map[string]ScheduleConfig `yaml:"schedules" mapstructure:"schedules" comment:"按采集器配置名称（与 collectors.<name> 相同，如 proc）或采集器名称（如 cpu-collector）覆盖采集间隔和超时"`
+	Backoff      BackoffConfig             `yaml:"backoff" mapstructure:"backoff" comment:"采集器连续失败后的退避策略"`
+	InitRetry    InitRetryConfig           `yaml:"init_retry" mapstructure:"init_retry" comment:"采集器初始化失败后的重试策略"`
 }
 
-// SysDataSourceConfig /sys 数据源配置（修复env标签冲突）
-type SysDataSourceConfig struct {
-	Enable         bool     `yaml:"enable" mapstructure:"enable" env:"COLLECTOR_SYS_ENABLE" comment:"是否启用/sys数据源" default:"false"`
-	IgnoreDisks    []string `yaml:"ignore_disks" mapstructure:"ignore_disks" env:"COLLECTOR_SYS_IGNORE_DISKS" comment:"忽略的磁盘列表（如/dev/sda）" default:"[]"`
-	IgnoreNetworks []string `yaml:"ignore_networks" mapstructure:"ignore_networks" env:"COLLECTOR_SYS_IGNORE_NETWORKS" comment:"忽略的网络接口列表（如eth0）" default:"[]"` // 修复yaml标签（原ignore_network → ignore_networks，复数一致）
+// BackoffConfig 采集器连续失败（返回错误或 panic）后的退避策略
+type BackoffConfig struct {
+	Threshold    int           `yaml:"threshold" mapstructure:"threshold" env:"MONITOR_BACKOFF_THRESHOLD" validate:"gte=0" comment:"连续失败多少次后开始退避（0使用默认值3）" default:"3"`
+	Initial      time.Duration `yaml:"initial" mapstructure:"initial" env:"MONITOR_BACKOFF_INITIAL" comment:"首次退避时间，之后每次失败翻倍" default:"10s"`
+	Max          time.Duration `yaml:"max" mapstructure:"max" env:"MONITOR_BACKOFF_MAX" comment:"最大退避时间" default:"5m"`
+	DisableAfter int           `yaml:"disable_after" mapstructure:"disable_after" env:"MONITOR_BACKOFF_DISABLE_AFTER" validate:"gte=0" comment:"连续失败多少次后停用采集器（0为从不停用）" default:"0"`
 }
 
-// CgroupDataSourceConfig Cgroup 数据源配置
-type CgroupDataSourceConfig struct {
-	Enable bool `yaml:"enable" mapstructure:"enable" env:"COLLECTOR_CGROUP_ENABLE" comment:"是否启用Cgroup数据源" default:"false"`
+// InitRetryConfig 采集器 Init 失败后的后台重试策略
+// 可选采集器初始化失败时跳过并在后台重试；collectors.<name>.required 为 true 的采集器启动时最多等待 required_wait，仍失败则启动失败
+type InitRetryConfig struct {
+	Initial      time.Duration `yaml:"initial" mapstructure:"initial" env:"MONITOR_INIT_RETRY_INITIAL" comment:"首次重试前的等待时间，之后每次失败翻倍" default:"10s"`
+	Max          time.Duration `yaml:"max" mapstructure:"max" env:"MONITOR_INIT_RETRY_MAX" comment:"最大重试间隔" default:"5m"`
+	RequiredWait time.Duration `yaml:"required_wait" mapstructure:"required_wait" env:"MONITOR_INIT_RETRY_REQUIRED_WAIT" validate:"gte=0" comment:"必需采集器初始化失败时启动阶段的最长等待时间（0为不等待，直接启动失败）" default:"30s"`
 }
 
-// ContainerRuntimeConfig 容器运行时API配置（简化结构体名）
-type ContainerRuntimeConfig struct {
-	Enable bool `yaml:"enable" mapstructure:"enable" env:"COLLECTOR_CONTAINER_ENABLE" comment:"是否启用容器运行时API" default:"false"`
+// ScheduleConfig 单个采集器的调度参数，0 表示使用采集器声明的默认值或全局 interval
+type ScheduleConfig struct {
+	Interval time.Duration `yaml:"interval" mapstructure:"interval" comment:"采集间隔（如30s）"`
+	Timeout  time.Duration `yaml:"timeout" mapstructure:"timeout" comment:"单次采集超时，超时后取消传给 Collect 的 context（默认等于采集间隔）"`
 }
 
 // ZapLogConfig 日志配置（修复标签笔误、补充默认值）
@@ -90,25 +98,27 @@ func NewDefaultConfig() *Config {
 			IdleTimeout:  60 * time.Second,
 		},
 		Monitor: MonitorConfig{
-			Interval: 2 * time.Second,
-			Collectors: CollectorConfig{
-				Proc: ProcDataSourceConfig{
-					Enable:          true,
-					CollectPerCore:  true,
-					LoadSampleCycle: 1 * time.Second,
-				},
-				Sys: SysDataSourceConfig{
-					Enable:         false,
-					IgnoreDisks:    []string{},
-					IgnoreNetworks: []string{},
-				},
-				Cgroup: CgroupDataSourceConfig{
-					Enable: false,
-				},
-				Container: ContainerRuntimeConfig{
-					Enable: false,
-				},
+			Interval:     2 * time.Second,
+			Mode:         "push",
+			ScrapeMinAge: time.Second,
+			StaleCycles:  3,
+			Workers:      4,
+			Backoff: BackoffConfig{
+				Threshold: 3,
+				Initial:   10 * time.Second,
+				Max:       5 * time.Minute,
+			},
+			InitRetry: InitRetryConfig{
+				Initial:      10 * time.Second,
+				Max:          5 * time.Minute,
+				RequiredWait: 30 * time.Second,
 			},
+			Collectors: map[string]map[string]any{},
+		},
+		Path: PathConfig{
+			ProcFS: "/proc",
+			SysFS:  "/sys",
+			RootFS: "/",
 		},
 		Log: ZapLogConfig{
 			Level:     "debug",
@@ -176,33 +186,48 @@ func LoadConfigWithCli(cmd *cobra.Command) (*Config, error) {
 	v.AutomaticEnv()
 	v.SetEnvKeyReplacer(strings.NewReplacer("_", "."))
 
-	// 4. 解码反序列化到结构体（支持 time.Duration）
-	decoderConfig := &mapstructure.DecoderConfig{
-		Metadata:         nil,
-		Result:           cfg,
+	// 4. 解码反序列化到结构体（此时: 默认配置 -> 文件配置(如有) -> Flags -> ENV的优先级）
+	if err := decode(v.AllSettings(), cfg); err != nil {
+		return nil, fmt.Errorf("decode config: %w", err)
+	}
+
+	// 5. 校验配置
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// decode 将配置 map 解码到结构体（支持 time.Duration 和逗号分隔的切片）
+func decode(input, out any) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           out,
 		WeaklyTypedInput: true,
 		DecodeHook: mapstructure.ComposeDecodeHookFunc(
 			mapstructure.StringToTimeDurationHookFunc(),
 			mapstructure.StringToSliceHookFunc(","),
 		),
-	}
-
-	decoder, err := mapstructure.NewDecoder(decoderConfig)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("new decoder: %w", err)
+		return fmt.Errorf("new decoder: %w", err)
 	}
+	return decoder.Decode(input)
+}
 
-	// 解码（此时: 默认配置 -> 文件配置(如有) -> Flags -> ENV的优先级）
-	if err := decoder.Decode(v.AllSettings()); err != nil {
-		return nil, fmt.Errorf("decode config: %w", err)
+// Decode 将某个配置段（如 collectors.<name>）解码到 out（结构体指针），并执行 validate 标签校验；
+// out 实现了 Validate() error 时一并调用，用于补默认值和跨字段校验
+func Decode(input, out any) error {
+	if err := decode(input, out); err != nil {
+		return err
 	}
-
-	// 5. 校验配置
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("validate config: %w", err)
+	if err := valid.Struct(out); err != nil {
+		return err
 	}
-
-	return cfg, nil
+	if v, ok := out.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return nil
 }
 
 // Validate 配置校验
@@ -224,5 +249,10 @@ func (c *Config) Validate() error {
 	if err := c.Log.Validate(); err != nil {
 		return err
 	}
+
+	// 	4，校验宿主机挂载点
+	if err := c.Path.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
@@ -4,7 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
-	"strings"
+	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -34,84 +35,101 @@ func (m *MonitorConfig) Validate() error {
 	if m.Interval < time.Second || m.Interval > 3600*time.Second {
 		return fmt.Errorf("metrics,interval must be between 1 and 3600 seconds, got %s", m.Interval)
 	}
-	// CollectorConfig 加盐
-
-	if err := m.Collectors.validate(); err != nil {
+	// collectors.<name> 的配置由对应的采集器工厂解码和校验（registers.Factory.Build）
+	for name, s := range m.Schedules {
+		if err := s.validate(name, m.Interval); err != nil {
+			return err
+		}
+	}
+	if m.Mode == "" {
+		m.Mode = "push"
+	}
+	if m.ScrapeMinAge < 0 {
+		return fmt.Errorf("metrics.scrape_min_age must not be negative, got %s", m.ScrapeMinAge)
+	}
+	if err := m.Backoff.validate(); err != nil {
+		return err
+	}
+	if err := m.InitRetry.validate(); err != nil {
 		return err
 	}
 	return nil
 }
 
-// 校验至少启用一个采集，否则没有意义
-func (col *CollectorConfig) validate() error {
-	if err := valid.Struct(col); err != nil {
-		return err
+// validate 填充退避策略默认值并校验上下限
+func (b *BackoffConfig) validate() error {
+	if b.Threshold == 0 {
+		b.Threshold = 3
 	}
-	// 	校验至少启用一个采集器，否则没有意义
-	if !col.Proc.Enable && !col.Sys.Enable && !col.Cgroup.Enable && !col.Container.Enable {
-		return fmt.Errorf("at least one collector must be enabled (proc/sys/cgroup/container)")
+	if b.Initial == 0 {
+		b.Initial = 10 * time.Second
 	}
-	//	 sys 采集器校验
-	if err := col.Sys.Validate(); err != nil {
-		return err
+	if b.Max == 0 {
+		b.Max = 5 * time.Minute
+	}
+	if b.Initial < 0 || b.Max < b.Initial {
+		return fmt.Errorf("metrics.backoff: initial (%s) must be positive and not exceed max (%s)", b.Initial, b.Max)
+	}
+	if b.DisableAfter > 0 && b.DisableAfter <= b.Threshold {
+		return fmt.Errorf("metrics.backoff.disable_after (%d) must be greater than threshold (%d)", b.DisableAfter, b.Threshold)
 	}
-
 	return nil
 }
 
-// Validate 忽略列表不能包含空字符串
-// 忽略的磁盘必须看起来像 "/dev/**"
-// 忽略的网络接口格式必须合法（不能有空格、不能是奇怪字符）
-// 重复项检测（避免配置写错）
-// sys 未启用时不校验
-// 不会误判，也不会过于严格导致难用。
-
-func (col *SysDataSourceConfig) Validate() error {
-	if err := valid.Struct(col); err != nil {
-		return err
+// validate 填充初始化重试策略默认值并校验上下限（required_wait 为 0 表示不等待，不填充默认值）
+func (i *InitRetryConfig) validate() error {
+	if i.Initial == 0 {
+		i.Initial = 10 * time.Second
 	}
-
-	// 如果 sys未启用时不参与校验
-	if !col.Enable {
-		return nil
+	if i.Max == 0 {
+		i.Max = 5 * time.Minute
 	}
-
-	// 	校验 IgnoreDisks
-	seenDisk := map[string]bool{}
-	for _, d := range col.IgnoreDisks {
-
-		if strings.TrimSpace(d) == "" {
-			return fmt.Errorf("sys.ignore_disks cannot contain empty string")
-		}
-		//	要求必须为/dev/xxx
-		if seenDisk[d] {
-			return fmt.Errorf("sys.ignore_disks contains duplicate disk name: %s", d)
-		}
-
-		seenDisk[d] = true
+	if i.Initial < 0 || i.Max < i.Initial {
+		return fmt.Errorf("metrics.init_retry: initial (%s) must be positive and not exceed max (%s)", i.Initial, i.Max)
 	}
+	if i.RequiredWait < 0 {
+		return fmt.Errorf("metrics.init_retry.required_wait must not be negative, got %s", i.RequiredWait)
+	}
+	return nil
+}
 
-	// 校验IgnoreNetworks
-	seeniface := map[string]bool{}
-	for _, iface := range col.IgnoreNetworks {
-		if strings.TrimSpace(iface) == "" {
-			return fmt.Errorf("sys.ignore_networks cannot contain empty string")
-		}
+// validate 校验单个采集器的调度参数：超时不能超过采集间隔，否则上一轮未结束下一轮就已到期
+func (s ScheduleConfig) validate(name string, global time.Duration) error {
+	if s.Interval < 0 || s.Timeout < 0 {
+		return fmt.Errorf("metrics.schedules.%s: interval and timeout must not be negative", name)
+	}
+	if s.Interval > 0 && s.Interval < 100*time.Millisecond {
+		return fmt.Errorf("metrics.schedules.%s.interval must be at least 100ms, got %s", name, s.Interval)
+	}
+	interval := s.Interval
+	if interval == 0 {
+		interval = global
+	}
+	if s.Timeout > interval {
+		return fmt.Errorf("metrics.schedules.%s.timeout (%s) must not exceed interval (%s)", name, s.Timeout, interval)
+	}
+	return nil
+}
 
-		//	网络接口名称要求：不能有空格，不能包含奇怪字符
-		// 通常linux 接口名如 etho,enp0sa,lo,docker0..
-		if strings.ContainsAny(iface, "\t\r\n") {
-			return fmt.Errorf("sys.ignore_networks: interface %q contains whitespace", iface)
+// Validate 挂载点必须是绝对路径且是已存在的目录；procfs 下必须能读到 stat，避免挂错目录后静默输出容器视图
+func (p *PathConfig) Validate() error {
+	if err := valid.Struct(p); err != nil {
+		return err
+	}
+	for name, dir := range map[string]string{"procfs": p.ProcFS, "sysfs": p.SysFS, "rootfs": p.RootFS} {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("path.%s must be an absolute path, got %q", name, dir)
 		}
-		if strings.ContainsAny(iface, "/\\") {
-			return fmt.Errorf("sys.ignore_networks: interface %q must not contain '/' or '\\\\", iface)
+		stat, err := os.Stat(dir)
+		if err != nil {
+			return fmt.Errorf("path.%s %q is not accessible: %w", name, dir, err)
 		}
-
-		// 重复项检查
-		if seeniface[iface] {
-			return fmt.Errorf("sys.ignore_networks duplicated entry: %q", iface)
+		if !stat.IsDir() {
+			return fmt.Errorf("path.%s %q is not a directory", name, dir)
 		}
-		seeniface[iface] = true
+	}
+	if _, err := os.Stat(filepath.Join(p.ProcFS, "stat")); err != nil {
+		return fmt.Errorf("path.procfs %q does not look like a procfs mount: %w", p.ProcFS, err)
 	}
 	return nil
 }
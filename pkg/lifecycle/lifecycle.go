@@ -0,0 +1,185 @@
+// Package lifecycle 统一管理 agent 各组件（采集器、HTTP 服务、sink 等）的启动和停止
+//
+// 组件按依赖顺序启动、按相反顺序停止：A 依赖 B 时 B 先于 A 启动、晚于 A 停止，
+// 没有依赖关系的组件保持注册顺序。所有组件共享一个根 context，收到 SIGINT/SIGTERM 时取消，
+// 之后先按停止顺序调用所有组件的 PreStop（此时所有组件仍在运行，如最后一次采集时 HTTP 服务仍可被抓取），
+// 再逐个调用 Stop。每个组件的 PreStop/Stop 有独立的截止时间，超时的组件不会阻塞其余组件的停止。
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/agent-collector/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultStopTimeout 组件未设置 StopTimeout 时 Stop 的截止时间
+const DefaultStopTimeout = 5 * time.Second
+
+// Component 一个受管理的组件，Start/Stop 均可为空
+type Component struct {
+	Name        string
+	DependsOn   []string                        // 依赖的组件名称：先于本组件启动，晚于本组件停止
+	Start       func(ctx context.Context) error // 非阻塞启动；ctx 为根 context，收到退出信号后取消，后台协程可用它感知退出
+	PreStop     func(ctx context.Context) error // 任何组件停止之前调用（如最后一次采集）；ctx 在 StopTimeout 后到期
+	Stop        func(ctx context.Context) error // 停止并释放资源；ctx 在 StopTimeout 后到期
+	StopTimeout time.Duration                   // PreStop 和 Stop 各自的截止时间，0 使用 DefaultStopTimeout
+}
+
+// Manager run-group 式的生命周期管理器
+type Manager struct {
+	signals    []os.Signal
+	components []Component
+}
+
+// New 创建生命周期管理器，signals 为触发退出的信号，为空时使用 SIGINT/SIGTERM
+func New(signals ...os.Signal) *Manager {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	return &Manager{signals: signals}
+}
+
+// Add 注册组件；名称重复、依赖不存在或循环依赖在 Run 时返回错误
+func (m *Manager) Add(c Component) {
+	m.components = append(m.components, c)
+}
+
+// Run 按依赖顺序启动所有组件并阻塞，直到 ctx 取消或收到退出信号，然后按相反顺序对已启动的组件
+// 先全部调用 PreStop、再逐个调用 Stop
+// 某个组件启动失败时不再启动后续组件，直接停止已启动的组件；返回启动错误和各组件 PreStop/Stop 错误的合并
+func (m *Manager) Run(ctx context.Context) error {
+	order, err := m.order()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(ctx, m.signals...)
+	defer stop()
+
+	started := make([]Component, 0, len(order))
+	var startErr error
+	for _, c := range order {
+		if ctx.Err() != nil {
+			break // 启动过程中收到退出信号
+		}
+		if c.Start != nil {
+			if err := c.Start(ctx); err != nil {
+				logger.Error("component failed to start", zap.String("component", c.Name), zap.Error(err))
+				startErr = fmt.Errorf("start %s: %w", c.Name, err)
+				break
+			}
+		}
+		started = append(started, c)
+		logger.Info("component started", zap.String("component", c.Name))
+	}
+
+	if startErr == nil {
+		logger.Info("service running, waiting for SIGINT/SIGTERM...", zap.Int("components", len(started)))
+		<-ctx.Done()
+		logger.Info("shutdown requested, stopping components", zap.Error(ctx.Err()))
+	}
+	// 恢复默认信号处理：停止过程中再次收到信号时直接退出进程
+	stop()
+
+	errs := []error{startErr}
+	for i := len(started) - 1; i >= 0; i-- {
+		errs = append(errs, runHook(started[i], "pre-stop", started[i].PreStop))
+	}
+	for i := len(started) - 1; i >= 0; i-- {
+		errs = append(errs, runHook(started[i], "stop", started[i].Stop))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	logger.Info("all components stopped")
+	return nil
+}
+
+// runHook 在截止时间内调用组件的 PreStop 或 Stop；超时后不再等待（hook 所在协程继续运行直到进程退出）
+func runHook(c Component, stage string, hook func(ctx context.Context) error) error {
+	if hook == nil {
+		return nil
+	}
+	timeout := c.StopTimeout
+	if timeout <= 0 {
+		timeout = DefaultStopTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- hook(ctx) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			logger.Error("component "+stage+" failed", zap.String("component", c.Name), zap.Error(err))
+			return fmt.Errorf("%s %s: %w", stage, c.Name, err)
+		}
+		logger.Info("component "+stage+" done", zap.String("component", c.Name), zap.Duration("elapsed", time.Since(start)))
+		return nil
+	case <-ctx.Done():
+		logger.Error("component "+stage+" did not finish before deadline", zap.String("component", c.Name), zap.Duration("timeout", timeout))
+		return fmt.Errorf("%s %s: %w", stage, c.Name, ctx.Err())
+	}
+}
+
+// order 按依赖关系对组件做拓扑排序，没有依赖关系的组件保持注册顺序
+func (m *Manager) order() ([]Component, error) {
+	index := make(map[string]int, len(m.components))
+	for i, c := range m.components {
+		if c.Name == "" {
+			return nil, fmt.Errorf("lifecycle: component #%d has no name", i)
+		}
+		if _, dup := index[c.Name]; dup {
+			return nil, fmt.Errorf("lifecycle: component %q registered twice", c.Name)
+		}
+		index[c.Name] = i
+	}
+	for _, c := range m.components {
+		for _, dep := range c.DependsOn {
+			if _, ok := index[dep]; !ok {
+				return nil, fmt.Errorf("lifecycle: component %q depends on unknown component %q", c.Name, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(m.components))
+	out := make([]Component, 0, len(m.components))
+	var visit func(i int) error
+	visit = func(i int) error {
+		c := m.components[i]
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("lifecycle: dependency cycle involving component %q", c.Name)
+		}
+		state[i] = visiting
+		for _, dep := range c.DependsOn {
+			if err := visit(index[dep]); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		out = append(out, c)
+		return nil
+	}
+	for i := range m.components {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
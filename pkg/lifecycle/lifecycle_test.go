@@ -0,0 +1,293 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/config"
+	"github.com/agent-collector/pkg/logger"
+)
+
+// TestMain 生命周期管理器依赖全局 logger，测试前先初始化到临时目录
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "lifecycle-test-logs")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if _, err := logger.InitLogger(&config.ZapLogConfig{Level: "error", Format: "json", Path: dir}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// recorder 按发生顺序记录各组件的 start/stop
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.events, ",")
+}
+
+func (r *recorder) component(name string, deps ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: deps,
+		Start: func(context.Context) error {
+			r.add("start " + name)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			r.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func TestRunOrdersByDependencies(t *testing.T) {
+	rec := &recorder{}
+	var rootCtx context.Context
+	m := New()
+	m.Add(rec.component("http", "collectors"))
+	collectors := rec.component("collectors", "sink")
+	start := collectors.Start
+	collectors.Start = func(ctx context.Context) error {
+		rootCtx = ctx
+		return start(ctx)
+	}
+	m.Add(collectors)
+	m.Add(rec.component("sink"))
+	m.Add(Component{Name: "hookless"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(rec.String(), "start http") {
+		if time.Now().After(deadline) {
+			t.Fatalf("components not started: %s", rec)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if rootCtx.Err() != nil {
+		t.Fatal("root context cancelled while running")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := "start sink,start collectors,start http,stop http,stop collectors,stop sink"
+	if got := rec.String(); got != want {
+		t.Errorf("events = %s\nwant     %s", got, want)
+	}
+	if rootCtx.Err() == nil {
+		t.Error("root context passed to Start not cancelled on shutdown")
+	}
+}
+
+// TestRunFinalCollectionServedBeforeHTTPStops collectors 的 PreStop（最后一次采集）在 HTTP 服务停止之前执行，
+// 停止过程中到达的抓取请求能拿到最后一次采集的结果
+func TestRunFinalCollectionServedBeforeHTTPStops(t *testing.T) {
+	var value atomic.Value
+	value.Store("initial")
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, value.Load().(string))
+	})}
+	url := "http://" + ln.Addr().String() + "/metrics"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var scraped string
+	m := New()
+	m.Add(Component{
+		Name: "collectors",
+		Start: func(context.Context) error {
+			value.Store("periodic")
+			return nil
+		},
+		PreStop: func(context.Context) error {
+			value.Store("final")
+			return nil
+		},
+	})
+	m.Add(Component{
+		Name:      "http",
+		DependsOn: []string{"collectors"},
+		Start: func(context.Context) error {
+			go func() { _ = srv.Serve(ln) }()
+			return nil
+		},
+		Stop: srv.Shutdown,
+	})
+	// scraper 依赖 http：在所有 PreStop 之后、HTTP 停止之前抓取一次，模拟停止过程中到达的抓取请求
+	m.Add(Component{
+		Name:      "scraper",
+		DependsOn: []string{"http"},
+		Start: func(context.Context) error {
+			cancel()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			scraped = string(body)
+			return err
+		},
+	})
+
+	if err := m.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if scraped != "final" {
+		t.Errorf("scrape during shutdown returned %q, want the final collection", scraped)
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Error("HTTP server still serving after Run returned")
+	}
+}
+
+// TestRunPreStopBeforeAnyStop 所有组件的 PreStop 按停止顺序执行完之后才开始调用 Stop
+func TestRunPreStopBeforeAnyStop(t *testing.T) {
+	rec := &recorder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	m := New()
+	for _, c := range []Component{rec.component("collectors"), rec.component("http", "collectors")} {
+		name := c.Name
+		c.PreStop = func(context.Context) error {
+			rec.add("pre-stop " + name)
+			return nil
+		}
+		m.Add(c)
+	}
+	m.Add(Component{Name: "trigger", Start: func(context.Context) error {
+		cancel()
+		return nil
+	}})
+
+	if err := m.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := "start collectors,start http,pre-stop http,pre-stop collectors,stop http,stop collectors"
+	if got := rec.String(); got != want {
+		t.Errorf("events = %s\nwant     %s", got, want)
+	}
+}
+
+func TestRunStartFailureStopsStarted(t *testing.T) {
+	rec := &recorder{}
+	m := New()
+	m.Add(rec.component("sink"))
+	m.Add(Component{
+		Name:      "collectors",
+		DependsOn: []string{"sink"},
+		Start:     func(context.Context) error { return errors.New("required collector init failed") },
+		Stop: func(context.Context) error {
+			rec.add("stop collectors")
+			return nil
+		},
+	})
+	m.Add(rec.component("http", "collectors"))
+
+	err := m.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "start collectors: required collector init failed") {
+		t.Fatalf("Run error = %v, want start failure", err)
+	}
+	if got := rec.String(); got != "start sink,stop sink" {
+		t.Errorf("events = %s, want only sink started and stopped", got)
+	}
+}
+
+func TestRunStopDeadline(t *testing.T) {
+	rec := &recorder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	m := New()
+	m.Add(rec.component("sink"))
+	m.Add(Component{
+		Name:        "stuck",
+		DependsOn:   []string{"sink"},
+		StopTimeout: 20 * time.Millisecond,
+		Start: func(context.Context) error {
+			cancel() // 启动完成后立即请求退出
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			time.Sleep(time.Second) // 不响应 ctx
+			return nil
+		},
+	})
+
+	start := time.Now()
+	err := m.Run(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "stop stuck") {
+		t.Fatalf("Run error = %v, want stuck component deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Run took %v, want the stuck Stop abandoned after its deadline", elapsed)
+	}
+	if !strings.Contains(rec.String(), "stop sink") {
+		t.Errorf("events = %s, want sink stopped after the stuck component", rec)
+	}
+}
+
+func TestRunInvalidComponents(t *testing.T) {
+	for name, components := range map[string][]Component{
+		"unnamed":      {{}},
+		"duplicate":    {{Name: "a"}, {Name: "a"}},
+		"unknown dep":  {{Name: "a", DependsOn: []string{"missing"}}},
+		"cycle":        {{Name: "a", DependsOn: []string{"b"}}, {Name: "b", DependsOn: []string{"a"}}},
+		"self depends": {{Name: "a", DependsOn: []string{"a"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			started := false
+			m := New()
+			for _, c := range components {
+				c.Start = func(context.Context) error {
+					started = true
+					return nil
+				}
+				m.Add(c)
+			}
+			if err := m.Run(context.Background()); err == nil {
+				t.Fatal("Run succeeded, want configuration error")
+			}
+			if started {
+				t.Error("components started despite invalid configuration")
+			}
+		})
+	}
+}
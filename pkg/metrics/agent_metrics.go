@@ -7,12 +7,19 @@ import "github.com/prometheus/client_golang/prometheus"
 // 核心作用：统计各采集器在运行过程中发生的采集错误累计次数
 // 标签说明：
 // collector: 采集器名称（如 "log_collector" 日志采集器、"metric_collector" 指标采集器），用于区分不同采集模块
+// 多个采集器调用时返回同一个实例，避免重复注册 panic
 func (m *MetricFactory) NewAgentCollectErrorsTotal() *prometheus.CounterVec {
+	m.shared.mu.Lock()
+	defer m.shared.mu.Unlock()
+	if m.shared.collectErrors != nil {
+		return m.shared.collectErrors
+	}
 	c := prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "agent_collect_errors_total",
 		Help: "Total collection errors",
 	}, []string{"collector"})
 	m.reg.MustRegister(c)
+	m.shared.collectErrors = c
 	return c
 }
 
@@ -27,11 +34,134 @@ func (m *MetricFactory) NewAgentCollectErrorsTotal() *prometheus.CounterVec {
 //
 //	覆盖从毫秒级到秒级的常见采集耗时场景
 func (m *MetricFactory) NewAgentCollectDurationSeconds() *prometheus.HistogramVec {
+	m.shared.mu.Lock()
+	defer m.shared.mu.Unlock()
+	if m.shared.collectDuration != nil {
+		return m.shared.collectDuration
+	}
 	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Name:    "agent_collect_duration_seconds",
 		Help:    "Collection duration per collector",
 		Buckets: prometheus.DefBuckets,
 	}, []string{"collector"})
 	m.reg.MustRegister(h)
+	m.shared.collectDuration = h
 	return h
 }
+
+// SeriesTracker 返回所有采集器共用的序列生命周期跟踪器（首次调用时创建）
+// 同时注册 agent_stale_series_deleted_total{collector}，统计因过期被删除的序列数
+func (m *MetricFactory) SeriesTracker() *SeriesTracker {
+	m.shared.mu.Lock()
+	defer m.shared.mu.Unlock()
+	if m.shared.seriesTracker != nil {
+		return m.shared.seriesTracker
+	}
+	deleted := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_stale_series_deleted_total",
+		Help: "Total series deleted after missing consecutive collection cycles",
+	}, []string{"collector"})
+	m.reg.MustRegister(deleted)
+	m.shared.seriesTracker = NewSeriesTracker(DefaultStaleCycles, deleted)
+	return m.shared.seriesTracker
+}
+
+// NewAgentCollectorOverrunsTotal 创建「采集超时次数」指标
+// 指标类型：Counter - 单次采集耗时超过该采集器的超时时间（context 已到期）时加 1
+// 标签说明：
+//
+//	collector: 采集器名称
+func (m *MetricFactory) NewAgentCollectorOverrunsTotal() *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_collector_overruns_total",
+		Help: "Total collections that exceeded the collector timeout",
+	}, []string{"collector"})
+	m.reg.MustRegister(c)
+	return c
+}
+
+// NewAgentCollectorSkippedCyclesTotal 创建「跳过的采集周期数」指标
+// 指标类型：Counter - 上一次采集耗时过长导致错过的调度周期数（错过的周期不补采）
+// 标签说明：
+//
+//	collector: 采集器名称
+func (m *MetricFactory) NewAgentCollectorSkippedCyclesTotal() *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_collector_skipped_cycles_total",
+		Help: "Total scheduled collection cycles skipped because the collector was still running",
+	}, []string{"collector"})
+	m.reg.MustRegister(c)
+	return c
+}
+
+// NewAgentCollectorState 创建「采集器健康状态」指标
+// 指标类型：Gauge - 每个采集器每种状态一条序列，当前状态为 1，其余为 0
+// 标签说明：
+//
+//	collector: 采集器名称
+//	state: healthy / degraded / backing_off / disabled
+func (m *MetricFactory) NewAgentCollectorState() *prometheus.GaugeVec {
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "agent_collector_state",
+		Help: "Current collector state (1 for the active state)",
+	}, []string{"collector", "state"})
+	m.reg.MustRegister(g)
+	return g
+}
+
+// NewAgentCollectorStateTransitionsTotal 创建「采集器状态切换次数」指标
+// 指标类型：Counter
+// 标签说明：
+//
+//	collector: 采集器名称
+//	from / to: 切换前后的状态
+func (m *MetricFactory) NewAgentCollectorStateTransitionsTotal() *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_collector_state_transitions_total",
+		Help: "Total collector state transitions",
+	}, []string{"collector", "from", "to"})
+	m.reg.MustRegister(c)
+	return c
+}
+
+// NewAgentCollectorConsecutiveFailures 创建「采集器连续失败次数」指标
+// 指标类型：Gauge - 采集成功后归零
+// 标签说明：
+//
+//	collector: 采集器名称
+func (m *MetricFactory) NewAgentCollectorConsecutiveFailures() *prometheus.GaugeVec {
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "agent_collector_consecutive_failures",
+		Help: "Consecutive failed collections per collector",
+	}, []string{"collector"})
+	m.reg.MustRegister(g)
+	return g
+}
+
+// NewAgentCollectorPanicsTotal 创建「采集器 panic 次数」指标
+// 指标类型：Counter - Collect 中的 panic 被恢复后计为一次失败
+// 标签说明：
+//
+//	collector: 采集器名称
+func (m *MetricFactory) NewAgentCollectorPanicsTotal() *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_collector_panics_total",
+		Help: "Total panics recovered from collector Collect calls",
+	}, []string{"collector"})
+	m.reg.MustRegister(c)
+	return c
+}
+
+// NewAgentCollectorInitFailed 创建「采集器初始化失败」指标
+// 指标类型：Gauge - Init 失败、正在后台重试时为 1，Init 成功后为 0
+// 标签说明：
+//
+//	collector: 采集器名称
+func (m *MetricFactory) NewAgentCollectorInitFailed() *prometheus.GaugeVec {
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "agent_collector_init_failed",
+		Help: "Whether the collector failed to initialize and is being retried (1) or initialized successfully (0)",
+	}, []string{"collector"})
+	m.reg.MustRegister(g)
+	return g
+}
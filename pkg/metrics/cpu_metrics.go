@@ -41,6 +41,82 @@ func (m *MetricFactory) NewCPULoad15() prometheus.Gauge {
 	return g
 }
 
+// NewCPULoad1PerCPU 按在线 CPU 数归一化的 1 分钟负载（>1 表示平均每个 CPU 有排队）
+func (m *MetricFactory) NewCPULoad1PerCPU() prometheus.Gauge {
+	g := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cpu_load1_per_cpu",
+		Help: "1 minute load average divided by the number of online CPUs",
+	})
+	m.reg.MustRegister(g)
+	return g
+}
+
+func (m *MetricFactory) NewCPULoad5PerCPU() prometheus.Gauge {
+	g := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cpu_load5_per_cpu",
+		Help: "5 minute load average divided by the number of online CPUs",
+	})
+	m.reg.MustRegister(g)
+	return g
+}
+
+func (m *MetricFactory) NewCPULoad15PerCPU() prometheus.Gauge {
+	g := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cpu_load15_per_cpu",
+		Help: "15 minute load average divided by the number of online CPUs",
+	})
+	m.reg.MustRegister(g)
+	return g
+}
+
+// NewCPULoadTasksRunning /proc/loadavg 中当前可运行的调度实体数
+func (m *MetricFactory) NewCPULoadTasksRunning() prometheus.Gauge {
+	g := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cpu_load_tasks_running",
+		Help: "Number of currently runnable kernel scheduling entities (from /proc/loadavg)",
+	})
+	m.reg.MustRegister(g)
+	return g
+}
+
+// NewCPULoadTasksTotal /proc/loadavg 中的调度实体总数
+func (m *MetricFactory) NewCPULoadTasksTotal() prometheus.Gauge {
+	g := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cpu_load_tasks_total",
+		Help: "Number of kernel scheduling entities that currently exist (from /proc/loadavg)",
+	})
+	m.reg.MustRegister(g)
+	return g
+}
+
+// NewCPURunQueueAvg1 instant_run_queue 模式：procs_running 的 1 分钟指数移动平均（不是内核 load average）
+func (m *MetricFactory) NewCPURunQueueAvg1() prometheus.Gauge {
+	g := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cpu_run_queue_avg1",
+		Help: "1 minute exponential moving average of procs_running (instant run-queue mode)",
+	})
+	m.reg.MustRegister(g)
+	return g
+}
+
+func (m *MetricFactory) NewCPURunQueueAvg5() prometheus.Gauge {
+	g := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cpu_run_queue_avg5",
+		Help: "5 minute exponential moving average of procs_running (instant run-queue mode)",
+	})
+	m.reg.MustRegister(g)
+	return g
+}
+
+func (m *MetricFactory) NewCPURunQueueAvg15() prometheus.Gauge {
+	g := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cpu_run_queue_avg15",
+		Help: "15 minute exponential moving average of procs_running (instant run-queue mode)",
+	})
+	m.reg.MustRegister(g)
+	return g
+}
+
 // NewCPUUsagePercent 创建并注册 CPU 总体使用率指标
 // 这个指标通常是一个 Gauge，因为它表示的是一个瞬时值
 func (m *MetricFactory) NewCPUUsagePercent() *prometheus.GaugeVec {
@@ -68,16 +144,125 @@ func (m *MetricFactory) NewCPUUsageModePercent() *prometheus.GaugeVec {
 	return gv
 }
 
+// NewCPUSecondsTotal 创建并注册按 CPU/模式划分的累计 CPU 时间指标
+// 这是一个 CounterVec，数值来自 /proc/stat 并按 USER_HZ 换算为秒，
+// 与采集间隔无关，Prometheus 端可以用 rate() 在任意 scrape 间隔下计算使用率
+func (m *MetricFactory) NewCPUSecondsTotal() *prometheus.CounterVec {
+	cv := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cpu_seconds_total",
+			Help: "Seconds the CPUs spent in each mode (user and nice exclude guest and guest_nice)",
+		},
+		[]string{"cpu", "mode"},
+	)
+	m.reg.MustRegister(cv)
+	return cv
+}
+
 // NewCPUInfo 创建并注册 CPU 信息指标
-// 这是一个 GaugeVec，带有 "cpu", "model", "cores" 等标签，值通常为 1，用于暴露元信息
+// 这是一个 GaugeVec，每个逻辑 CPU 一条序列，值固定为 1，用于暴露元信息：
+// cpu（逻辑CPU序号）、model（型号）、cores（物理核心总数）、
+// package（socket）、die、core（物理核心ID）、node（NUMA 节点）
 func (m *MetricFactory) NewCPUInfo() *prometheus.GaugeVec {
 	gv := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "cpu_info",
-			Help: "CPU information (model, cores, etc.)",
+			Help: "CPU information per logical CPU (model, cores, topology)",
 		},
-		[]string{"cpu", "model", "cores"}, // 标签名，用于携带 CPU 信息
+		[]string{"cpu", "model", "cores", "package", "die", "core", "thread_siblings", "node"}, // 标签名，用于携带 CPU 信息
 	)
 	m.reg.MustRegister(gv)
 	return gv
 }
+
+// NewCPUPackages 物理封装（socket）数
+func (m *MetricFactory) NewCPUPackages() prometheus.Gauge {
+	g := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cpu_packages",
+		Help: "Number of physical CPU packages (sockets)",
+	})
+	m.reg.MustRegister(g)
+	return g
+}
+
+// NewCPUPhysicalCores 物理核心数（按 package/die/core 去重）
+func (m *MetricFactory) NewCPUPhysicalCores() prometheus.Gauge {
+	g := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cpu_physical_cores",
+		Help: "Number of physical CPU cores",
+	})
+	m.reg.MustRegister(g)
+	return g
+}
+
+// NewCPULogicalCPUs 逻辑CPU数
+func (m *MetricFactory) NewCPULogicalCPUs() prometheus.Gauge {
+	g := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cpu_logical_cpus",
+		Help: "Number of logical CPUs",
+	})
+	m.reg.MustRegister(g)
+	return g
+}
+
+// NewCPUBurstUsageMaxPercent 采集窗口内高频采样（如100ms）得到的最大使用率
+func (m *MetricFactory) NewCPUBurstUsageMaxPercent() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cpu_burst_usage_max_percent",
+		Help: "Maximum CPU usage percentage sampled at sub-interval during the last collection window",
+	}, []string{"cpu"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewCPUBurstUsageP95Percent 采集窗口内高频采样得到的使用率 p95
+func (m *MetricFactory) NewCPUBurstUsageP95Percent() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cpu_burst_usage_p95_percent",
+		Help: "95th percentile of CPU usage percentage sampled at sub-interval during the last collection window",
+	}, []string{"cpu"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewCPUBurstAboveThresholdSeconds 采集窗口内使用率不低于阈值的累计时间
+func (m *MetricFactory) NewCPUBurstAboveThresholdSeconds() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cpu_burst_above_threshold_seconds",
+		Help: "Time in seconds CPU usage stayed at or above the burst threshold during the last collection window",
+	}, []string{"cpu"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewCPUMicrocodeInfo CPU 型号标识和 microcode 版本（值固定为 1）
+// 标签：package、vendor、family、model、stepping、microcode，按 package 去重，同一 package 混用不同 microcode 时会出现多条
+func (m *MetricFactory) NewCPUMicrocodeInfo() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cpu_microcode_info",
+		Help: "CPU identification and microcode revision per package",
+	}, []string{"package", "vendor", "family", "model", "stepping", "microcode"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewCPUFlagInfo CPU 特性 flags（/proc/cpuinfo flags 字段，ARM 为 Features），每个 flag 一条，值固定为 1
+func (m *MetricFactory) NewCPUFlagInfo() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cpu_flag_info",
+		Help: "CPU feature flags reported in /proc/cpuinfo",
+	}, []string{"flag"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewCPUVulnerabilityInfo 内核报告的 CPU 漏洞状态（/sys/devices/system/cpu/vulnerabilities），值固定为 1
+// status: vulnerable / mitigation / not_affected / unknown；mitigation 为内核给出的缓解措施描述
+func (m *MetricFactory) NewCPUVulnerabilityInfo() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cpu_vulnerability_info",
+		Help: "CPU vulnerability status and mitigation reported by the kernel",
+	}, []string{"vulnerability", "status", "mitigation"})
+	m.reg.MustRegister(gv)
+	return gv
+}
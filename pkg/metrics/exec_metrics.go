@@ -0,0 +1,60 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// NewExecExitCode 创建「命令退出码」指标
+// 指标类型：Gauge - 最近一次执行的退出码，启动失败或被信号杀死时为 -1
+// 标签说明：
+// command: 配置中的命令名称
+func (m *MetricFactory) NewExecExitCode() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "exec_command_exit_code",
+		Help: "Exit code of the last run of the command, -1 if it failed to start or was killed",
+	}, []string{"command"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewExecDurationSeconds 创建「命令执行耗时」指标
+// 指标类型：Gauge - 最近一次执行耗时（秒）
+func (m *MetricFactory) NewExecDurationSeconds() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "exec_command_duration_seconds",
+		Help: "Duration of the last run of the command in seconds",
+	}, []string{"command"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewExecTimeout 创建「命令是否超时」指标
+// 指标类型：Gauge - 最近一次执行超时并被杀掉为 1，否则为 0
+func (m *MetricFactory) NewExecTimeout() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "exec_command_timeout",
+		Help: "1 if the last run of the command timed out and its process group was killed",
+	}, []string{"command"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewExecParseError 创建「命令输出解析失败」指标
+// 指标类型：Gauge - 最近一次输出解析失败为 1，否则为 0
+func (m *MetricFactory) NewExecParseError() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "exec_command_parse_error",
+		Help: "1 if the output of the last run of the command could not be parsed",
+	}, []string{"command"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewExecLastRunTimestamp 创建「命令最近执行时间」指标
+// 指标类型：Gauge - 最近一次执行完成的 Unix 时间戳（秒）
+func (m *MetricFactory) NewExecLastRunTimestamp() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "exec_command_last_run_timestamp_seconds",
+		Help: "Unix time when the last run of the command finished",
+	}, []string{"command"})
+	m.reg.MustRegister(gv)
+	return gv
+}
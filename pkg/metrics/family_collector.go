@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// FamilyCollector 将外部解析得到的 MetricFamily（textfile、脚本输出等）合并到 registry 中暴露
+// 属于 unchecked collector：Describe 不输出任何描述，指标集合由最近一次 Update 决定
+type FamilyCollector struct {
+	mu       sync.RWMutex
+	families []*dto.MetricFamily
+}
+
+// NewFamilyCollector 创建并注册外部指标族收集器
+func (m *MetricFactory) NewFamilyCollector() *FamilyCollector {
+	fc := &FamilyCollector{}
+	m.reg.MustRegister(fc)
+	return fc
+}
+
+// Update 整体替换当前暴露的指标族
+func (f *FamilyCollector) Update(families []*dto.MetricFamily) {
+	sorted := make([]*dto.MetricFamily, len(families))
+	copy(sorted, families)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GetName() < sorted[j].GetName() })
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.families = sorted
+}
+
+// Describe 实现 prometheus.Collector，unchecked collector 不输出描述
+func (f *FamilyCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect 实现 prometheus.Collector，把 dto 指标转换为常量指标输出
+// 单条指标转换失败时直接跳过，避免一份坏数据影响整个 scrape
+func (f *FamilyCollector) Collect(ch chan<- prometheus.Metric) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, mf := range f.families {
+		for _, m := range mf.GetMetric() {
+			if metric, err := ConstMetricFromDTO(mf, m); err == nil {
+				ch <- metric
+			}
+		}
+	}
+}
+
+// ConstMetricFromDTO 将单条 dto.Metric 转换为 prometheus 常量指标
+func ConstMetricFromDTO(mf *dto.MetricFamily, m *dto.Metric) (prometheus.Metric, error) {
+	names := make([]string, 0, len(m.GetLabel()))
+	values := make([]string, 0, len(m.GetLabel()))
+	for _, lp := range m.GetLabel() {
+		names = append(names, lp.GetName())
+		values = append(values, lp.GetValue())
+	}
+	help := mf.GetHelp()
+	if help == "" {
+		help = "Metric read from external source"
+	}
+	desc := prometheus.NewDesc(mf.GetName(), help, names, nil)
+
+	switch mf.GetType() {
+	case dto.MetricType_COUNTER:
+		return prometheus.NewConstMetric(desc, prometheus.CounterValue, m.GetCounter().GetValue(), values...)
+	case dto.MetricType_GAUGE:
+		return prometheus.NewConstMetric(desc, prometheus.GaugeValue, m.GetGauge().GetValue(), values...)
+	case dto.MetricType_SUMMARY:
+		quantiles := make(map[float64]float64, len(m.GetSummary().GetQuantile()))
+		for _, q := range m.GetSummary().GetQuantile() {
+			quantiles[q.GetQuantile()] = q.GetValue()
+		}
+		return prometheus.NewConstSummary(desc, m.GetSummary().GetSampleCount(), m.GetSummary().GetSampleSum(), quantiles, values...)
+	case dto.MetricType_HISTOGRAM:
+		buckets := make(map[float64]uint64, len(m.GetHistogram().GetBucket()))
+		for _, b := range m.GetHistogram().GetBucket() {
+			buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+		}
+		return prometheus.NewConstHistogram(desc, m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum(), buckets, values...)
+	default:
+		return prometheus.NewConstMetric(desc, prometheus.UntypedValue, m.GetUntyped().GetValue(), values...)
+	}
+}
@@ -0,0 +1,16 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// NewKernelEventsTotal 创建「内核日志事件数」指标
+// 指标类型：Counter - agent 启动后观测到的内核事件累计数
+// 标签说明：
+// class: 事件分类（oom_kill/hung_task/segfault/fs_error/io_error/link_flap/mce）
+func (m *MetricFactory) NewKernelEventsTotal() *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kernel_events_total",
+		Help: "Total kernel log events by class observed since agent start",
+	}, []string{"class"})
+	m.reg.MustRegister(c)
+	return c
+}
@@ -1,11 +1,26 @@
 package metrics
 
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
 // MetricFactory 指标工厂，用于统一创建指标（counter/gauge/histogram）。
 type MetricFactory struct {
-	reg Registers
+	reg    Registers
+	shared *sharedMetrics
+}
+
+// sharedMetrics 多个采集器共用的 agent 自身指标，只注册一次
+type sharedMetrics struct {
+	mu              sync.Mutex
+	collectErrors   *prometheus.CounterVec
+	collectDuration *prometheus.HistogramVec
+	seriesTracker   *SeriesTracker
 }
 
 // NewMetricFactory 创建指标工厂
 func NewMetricFactory(reg Registers) *MetricFactory {
-	return &MetricFactory{reg: reg}
+	return &MetricFactory{reg: reg, shared: &sharedMetrics{}}
 }
@@ -19,11 +19,83 @@ type NetCollectorMetrics struct {
 
 // CPUCollectorMetrics  CPU采集器指标结构体
 type CPUCollectorMetrics struct {
-	UsageRatio       *prometheus.GaugeVec
-	Load1            prometheus.Gauge
-	Load5            prometheus.Gauge
-	Load15           prometheus.Gauge
-	UsagePercent     *prometheus.GaugeVec
-	UsageModePercent *prometheus.GaugeVec
-	CPUInfo          *prometheus.GaugeVec
+	UsageRatio        *prometheus.GaugeVec
+	Load1             prometheus.Gauge // Load*/Tasks* 只在 kernel 模式、RunQueue* 只在 instant_run_queue 模式创建，其余为 nil
+	Load5             prometheus.Gauge
+	Load15            prometheus.Gauge
+	Load1PerCPU       prometheus.Gauge // load1 / 在线CPU数
+	Load5PerCPU       prometheus.Gauge
+	Load15PerCPU      prometheus.Gauge
+	TasksRunning      prometheus.Gauge // /proc/loadavg 第4个字段：可运行任务数
+	TasksTotal        prometheus.Gauge // /proc/loadavg 第4个字段：任务总数
+	RunQueue1         prometheus.Gauge // instant_run_queue 模式：procs_running 的 1 分钟指数移动平均
+	RunQueue5         prometheus.Gauge
+	RunQueue15        prometheus.Gauge
+	UsagePercent      *prometheus.GaugeVec
+	UsageModePercent  *prometheus.GaugeVec
+	SecondsTotal      *prometheus.CounterVec // 各模式累计 CPU 时间（秒）
+	CPUInfo           *prometheus.GaugeVec   // 每个逻辑 CPU 一条，携带型号和拓扑标签
+	Packages          prometheus.Gauge       // 物理封装（socket）数
+	PhysicalCores     prometheus.Gauge       // 物理核心数
+	LogicalCPUs       prometheus.Gauge       // 逻辑CPU数
+	MicrocodeInfo     *prometheus.GaugeVec   // 型号标识（vendor/family/model/stepping/microcode），按 package 去重
+	FlagInfo          *prometheus.GaugeVec   // CPU 特性 flags，每个 flag 一条
+	VulnerabilityInfo *prometheus.GaugeVec   // 内核报告的 CPU 漏洞及缓解状态
+	BurstMax          *prometheus.GaugeVec   // 采集窗口内高频采样的最大使用率
+	BurstP95          *prometheus.GaugeVec   // 采集窗口内高频采样的使用率 p95
+	BurstAbove        *prometheus.GaugeVec   // 采集窗口内使用率超过阈值的时间（秒）
+}
+
+// SessionCollectorMetrics 登录会话采集器指标结构体
+type SessionCollectorMetrics struct {
+	Active *prometheus.GaugeVec // 当前活跃会话数（按用户/终端类型/来源分类）
+}
+
+// TextfileCollectorMetrics textfile采集器指标结构体
+type TextfileCollectorMetrics struct {
+	ScrapeError *prometheus.GaugeVec // 文件解析是否失败（1/0）
+	Mtime       *prometheus.GaugeVec // 文件修改时间
+	Families    *FamilyCollector     // 文件中解析出的指标族
+}
+
+// ExecCollectorMetrics 脚本执行采集器指标结构体
+type ExecCollectorMetrics struct {
+	ExitCode    *prometheus.GaugeVec // 最近一次退出码
+	Duration    *prometheus.GaugeVec // 最近一次执行耗时（秒）
+	Timeout     *prometheus.GaugeVec // 最近一次是否超时（1/0）
+	ParseError  *prometheus.GaugeVec // 最近一次输出解析是否失败（1/0）
+	LastRunTime *prometheus.GaugeVec // 最近一次执行完成时间
+	Families    *FamilyCollector     // 命令输出解析出的指标族
+}
+
+// ProbeCollectorMetrics 依赖探测采集器指标结构体
+type ProbeCollectorMetrics struct {
+	Success        *prometheus.GaugeVec // 探测是否成功（1/0）
+	Duration       *prometheus.GaugeVec // 探测总耗时（秒）
+	PhaseDuration  *prometheus.GaugeVec // 各阶段耗时（resolve/connect/tls/first_byte）
+	HTTPStatusCode *prometheus.GaugeVec // HTTP 状态码
+	HTTPBodyMatch  *prometheus.GaugeVec // 响应体是否匹配正则（1/0）
+	DNSAnswers     *prometheus.GaugeVec // DNS 应答记录数
+	CertExpiry     *prometheus.GaugeVec // 证书链中最早过期时间
+}
+
+// KmsgCollectorMetrics 内核日志事件采集器指标结构体
+type KmsgCollectorMetrics struct {
+	Events *prometheus.CounterVec // 按分类统计的内核事件数（累计）
+}
+
+// SchedstatCollectorMetrics 调度统计采集器指标结构体
+type SchedstatCollectorMetrics struct {
+	RunningSeconds *prometheus.CounterVec // 各 CPU 上任务累计运行时间（秒）
+	WaitingSeconds *prometheus.CounterVec // 各 CPU 上任务在运行队列中累计等待时间（秒）
+	Timeslices     *prometheus.CounterVec // 各 CPU 上累计运行的时间片数
+}
+
+// PluginCollectorMetrics 进程外插件采集器指标结构体
+type PluginCollectorMetrics struct {
+	Up              *prometheus.GaugeVec   // 插件进程是否在运行且已完成握手（1/0）
+	Restarts        *prometheus.CounterVec // 插件进程重启次数（累计）
+	CollectDuration *prometheus.GaugeVec   // 最近一次采集耗时（秒）
+	CollectError    *prometheus.GaugeVec   // 最近一次采集是否失败（1/0）
+	Families        *FamilyCollector       // 插件返回的指标族
 }
@@ -0,0 +1,49 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// NewPluginUp 创建「插件是否可用」指标
+// 指标类型：Gauge - 插件进程在运行且已完成握手为 1，否则为 0（崩溃后等待重启期间为 0）
+// 标签说明：
+// plugin: 插件可执行文件名
+func (m *MetricFactory) NewPluginUp() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "plugin_up",
+		Help: "1 if the plugin process is running and has completed the handshake",
+	}, []string{"plugin"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewPluginRestartsTotal 创建「插件重启次数」指标
+// 指标类型：Counter - 插件进程退出或启动失败后被重新启动的次数
+func (m *MetricFactory) NewPluginRestartsTotal() *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "plugin_restarts_total",
+		Help: "Total number of times the plugin process was restarted after exiting or failing to start",
+	}, []string{"plugin"})
+	m.reg.MustRegister(c)
+	return c
+}
+
+// NewPluginCollectDurationSeconds 创建「插件采集耗时」指标
+// 指标类型：Gauge - 最近一次 collect 请求的耗时（秒）
+func (m *MetricFactory) NewPluginCollectDurationSeconds() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "plugin_collect_duration_seconds",
+		Help: "Duration of the last collect request to the plugin in seconds",
+	}, []string{"plugin"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewPluginCollectError 创建「插件采集失败」指标
+// 指标类型：Gauge - 最近一次采集失败（超时、插件返回错误或输出无法解析）为 1，否则为 0
+func (m *MetricFactory) NewPluginCollectError() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "plugin_collect_error",
+		Help: "1 if the last collect request to the plugin failed or its output could not be parsed",
+	}, []string{"plugin"})
+	m.reg.MustRegister(gv)
+	return gv
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// NewProbeSuccess 创建「探测是否成功」指标
+// 指标类型：Gauge - 成功为 1，失败为 0
+// 标签说明：
+// target: 配置中的目标名称
+// type: 探测类型（tcp/http/dns）
+func (m *MetricFactory) NewProbeSuccess() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "probe_success",
+		Help: "Whether the probe of the target succeeded",
+	}, []string{"target", "type"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewProbeDurationSeconds 创建「探测总耗时」指标
+// 指标类型：Gauge - 最近一次探测总耗时（秒）
+func (m *MetricFactory) NewProbeDurationSeconds() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "probe_duration_seconds",
+		Help: "Duration of the last probe of the target in seconds",
+	}, []string{"target", "type"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewProbePhaseDurationSeconds 创建「探测各阶段耗时」指标
+// 指标类型：Gauge - 最近一次探测各阶段耗时（秒）
+// 标签说明：
+// phase: resolve（域名解析）/connect（建连）/tls（TLS握手）/first_byte（连接就绪后到收到响应首字节）
+// 本次探测未经历的阶段（如失败在建连前、非 TLS 目标）不输出
+func (m *MetricFactory) NewProbePhaseDurationSeconds() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "probe_phase_duration_seconds",
+		Help: "Duration of each phase of the last probe of the target in seconds",
+	}, []string{"target", "type", "phase"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewProbeHTTPStatusCode 创建「HTTP 状态码」指标
+func (m *MetricFactory) NewProbeHTTPStatusCode() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "probe_http_status_code",
+		Help: "HTTP status code of the last probe of the target",
+	}, []string{"target"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewProbeHTTPBodyMatch 创建「响应体正则匹配」指标
+// 指标类型：Gauge - 匹配为 1，否则为 0（仅配置了 body_regex 的目标输出）
+func (m *MetricFactory) NewProbeHTTPBodyMatch() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "probe_http_body_match",
+		Help: "Whether the HTTP response body matched the configured regex",
+	}, []string{"target"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewProbeDNSAnswers 创建「DNS 应答记录数」指标
+func (m *MetricFactory) NewProbeDNSAnswers() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "probe_dns_answers",
+		Help: "Number of records in the DNS answer of the last probe of the target",
+	}, []string{"target"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewProbeTLSCertExpiry 创建「证书过期时间」指标
+// 指标类型：Gauge - 对端证书链中最早过期证书的 NotAfter（Unix 时间戳，秒）
+func (m *MetricFactory) NewProbeTLSCertExpiry() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "probe_tls_cert_expiry_timestamp_seconds",
+		Help: "Earliest NotAfter of the certificates presented by the target, in unixtime",
+	}, []string{"target"})
+	m.reg.MustRegister(gv)
+	return gv
+}
@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -39,3 +41,72 @@ func (p *promRegistry) Unregister(collector prometheus.Collector) bool {
 func (p *promRegistry) Register(collector prometheus.Collector) error {
 	return p.registry.Register(collector)
 }
+
+// CollectorSet 只记录注册进来的指标而不直接暴露，本身实现 prometheus.Collector
+// collect-on-scrape 模式下采集器指标注册到这里，由外层在 scrape 时先触发采集再统一输出
+// 内部用一个独立的 prometheus.Registry 做重复注册/描述冲突校验，保持与直接注册相同的报错行为
+type CollectorSet struct {
+	check *prometheus.Registry
+
+	mu         sync.RWMutex
+	collectors []prometheus.Collector
+}
+
+// NewCollectorSet 创建空的指标集合
+func NewCollectorSet() *CollectorSet {
+	return &CollectorSet{check: prometheus.NewRegistry()}
+}
+
+// MustRegister 实现 prometheus.Registerer
+func (s *CollectorSet) MustRegister(collectors ...prometheus.Collector) {
+	for _, c := range collectors {
+		if err := s.Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register 实现自定义 Registry 接口
+func (s *CollectorSet) Register(collector prometheus.Collector) error {
+	if err := s.check.Register(collector); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collectors = append(s.collectors, collector)
+	return nil
+}
+
+// Unregister 实现 prometheus.Registerer
+func (s *CollectorSet) Unregister(collector prometheus.Collector) bool {
+	if !s.check.Unregister(collector) {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, c := range s.collectors {
+		if c == collector {
+			s.collectors = append(s.collectors[:i], s.collectors[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Describe 实现 prometheus.Collector，输出所有已注册指标的描述
+func (s *CollectorSet) Describe(ch chan<- *prometheus.Desc) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.collectors {
+		c.Describe(ch)
+	}
+}
+
+// Collect 实现 prometheus.Collector，输出所有已注册指标的当前值
+func (s *CollectorSet) Collect(ch chan<- prometheus.Metric) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.collectors {
+		c.Collect(ch)
+	}
+}
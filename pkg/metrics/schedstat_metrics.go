@@ -0,0 +1,36 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// NewSchedstatRunningSecondsTotal 创建「CPU 上任务累计运行时间」指标（/proc/schedstat 第7个字段，纳秒换算为秒）
+// 标签说明：
+// cpu: 逻辑CPU（cpuN）
+func (m *MetricFactory) NewSchedstatRunningSecondsTotal() *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "schedstat_running_seconds_total",
+		Help: "Total time in seconds tasks spent running on this CPU",
+	}, []string{"cpu"})
+	m.reg.MustRegister(c)
+	return c
+}
+
+// NewSchedstatWaitingSecondsTotal 创建「运行队列累计等待时间」指标（/proc/schedstat 第8个字段）
+// 平均调度延迟 = rate(waiting_seconds_total) / rate(timeslices_total)
+func (m *MetricFactory) NewSchedstatWaitingSecondsTotal() *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "schedstat_waiting_seconds_total",
+		Help: "Total time in seconds tasks spent waiting on the run queue of this CPU",
+	}, []string{"cpu"})
+	m.reg.MustRegister(c)
+	return c
+}
+
+// NewSchedstatTimeslicesTotal 创建「累计时间片数」指标（/proc/schedstat 第9个字段）
+func (m *MetricFactory) NewSchedstatTimeslicesTotal() *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "schedstat_timeslices_total",
+		Help: "Total number of timeslices run on this CPU",
+	}, []string{"cpu"})
+	m.reg.MustRegister(c)
+	return c
+}
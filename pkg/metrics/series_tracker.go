@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultStaleCycles 序列连续未写入多少个采集周期后被删除
+const DefaultStaleCycles = 3
+
+// LabelDeleter 可按标签值删除序列的指标向量（GaugeVec/CounterVec/HistogramVec 均满足）
+type LabelDeleter interface {
+	DeleteLabelValues(lvs ...string) bool
+}
+
+// SeriesTracker 所有采集器共用的序列生命周期跟踪器
+// 记录每个采集周期写入过的标签组合，连续 staleCycles 个周期未写入的序列会被 DeleteLabelValues，
+// 避免 CPU 下线、网卡删除、容器退出后指标一直停留在最后一次的值
+type SeriesTracker struct {
+	mu          sync.Mutex
+	staleCycles uint64
+	scopes      map[string]*SeriesScope
+	deleted     *prometheus.CounterVec
+}
+
+// NewSeriesTracker 创建序列跟踪器，staleCycles <= 0 时使用默认值
+func NewSeriesTracker(staleCycles int, deleted *prometheus.CounterVec) *SeriesTracker {
+	if staleCycles <= 0 {
+		staleCycles = DefaultStaleCycles
+	}
+	return &SeriesTracker{
+		staleCycles: uint64(staleCycles),
+		scopes:      make(map[string]*SeriesScope),
+		deleted:     deleted,
+	}
+}
+
+// SetStaleCycles 调整过期周期数（只影响之后的 End 判断）
+func (t *SeriesTracker) SetStaleCycles(staleCycles int) {
+	if staleCycles <= 0 {
+		staleCycles = DefaultStaleCycles
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.staleCycles = uint64(staleCycles)
+	for _, s := range t.scopes {
+		s.mu.Lock()
+		s.staleCycles = t.staleCycles
+		s.mu.Unlock()
+	}
+}
+
+// Scope 返回某个采集器的跟踪作用域，同名返回同一个实例
+// 各采集器的采集周期相互独立，过期判断只在作用域内进行
+func (t *SeriesTracker) Scope(owner string) *SeriesScope {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s, ok := t.scopes[owner]; ok {
+		return s
+	}
+	s := &SeriesScope{
+		owner:       owner,
+		staleCycles: t.staleCycles,
+		series:      make(map[seriesID]*trackedSeries),
+		deleted:     t.deleted,
+	}
+	t.scopes[owner] = s
+	return s
+}
+
+// StaleSeries 被删除的序列，采集器可据此清理自身缓存的状态
+type StaleSeries struct {
+	Vec    LabelDeleter
+	Labels []string
+}
+
+// seriesID 指标向量 + 标签值组合
+type seriesID struct {
+	vec LabelDeleter
+	key string
+}
+
+type trackedSeries struct {
+	labels   []string
+	lastSeen uint64 // 最近一次写入时的周期号
+}
+
+// SeriesScope 单个采集器的序列跟踪作用域
+// 用法：每轮采集开始调用 Begin，写入序列时调用 Touch，采集结束调用 End 清理过期序列
+type SeriesScope struct {
+	mu          sync.Mutex
+	owner       string
+	staleCycles uint64
+	cycle       uint64
+	series      map[seriesID]*trackedSeries
+	deleted     *prometheus.CounterVec
+}
+
+// Begin 开始新的采集周期
+func (s *SeriesScope) Begin() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cycle++
+}
+
+// Touch 标记本周期写入了 vec 中标签值为 lvs 的序列
+func (s *SeriesScope) Touch(vec LabelDeleter, lvs ...string) {
+	id := seriesID{vec: vec, key: strings.Join(lvs, "\xff")}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ts, ok := s.series[id]; ok {
+		ts.lastSeen = s.cycle
+		return
+	}
+	s.series[id] = &trackedSeries{labels: append([]string(nil), lvs...), lastSeen: s.cycle}
+}
+
+// End 结束当前采集周期，删除连续 staleCycles 个周期未写入的序列并返回
+func (s *SeriesScope) End() []StaleSeries {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var stale []StaleSeries
+	for id, ts := range s.series {
+		if s.cycle-ts.lastSeen < s.staleCycles {
+			continue
+		}
+		id.vec.DeleteLabelValues(ts.labels...)
+		delete(s.series, id)
+		stale = append(stale, StaleSeries{Vec: id.vec, Labels: ts.labels})
+	}
+	if len(stale) > 0 && s.deleted != nil {
+		s.deleted.WithLabelValues(s.owner).Add(float64(len(stale)))
+	}
+	return stale
+}
+
+// Len 当前跟踪的序列数
+func (s *SeriesScope) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.series)
+}
@@ -0,0 +1,57 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSeriesScopeDeletesStaleSeries(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	factory := metrics.NewMetricFactory(metrics.NewPromRegistry(reg))
+	tracker := factory.SeriesTracker()
+	tracker.SetStaleCycles(2)
+	scope := tracker.Scope("test")
+
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_gauge"}, []string{"cpu"})
+	reg.MustRegister(gv)
+
+	write := func(cpus ...string) []metrics.StaleSeries {
+		scope.Begin()
+		for _, cpu := range cpus {
+			gv.WithLabelValues(cpu).Set(1)
+			scope.Touch(gv, cpu)
+		}
+		return scope.End()
+	}
+
+	write("cpu0", "cpu1")
+	if stale := write("cpu0"); len(stale) != 0 {
+		t.Fatalf("series deleted after one missed cycle: %v", stale)
+	}
+	if n := testutil.CollectAndCount(gv); n != 2 {
+		t.Fatalf("expected 2 series, got %d", n)
+	}
+
+	stale := write("cpu0")
+	if len(stale) != 1 || stale[0].Labels[0] != "cpu1" {
+		t.Fatalf("expected cpu1 to be stale, got %v", stale)
+	}
+	if n := testutil.CollectAndCount(gv); n != 1 {
+		t.Fatalf("expected 1 series after deletion, got %d", n)
+	}
+	if scope.Len() != 1 {
+		t.Fatalf("expected 1 tracked series, got %d", scope.Len())
+	}
+	expected := `
+# HELP agent_stale_series_deleted_total Total series deleted after missing consecutive collection cycles
+# TYPE agent_stale_series_deleted_total counter
+agent_stale_series_deleted_total{collector="test"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "agent_stale_series_deleted_total"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,18 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// NewSessionsActive 创建「当前登录会话数」指标
+// 指标类型：Gauge - 每次采集时根据 utmp 中的 USER_PROCESS 记录重新统计
+// 标签说明：
+// user: 登录用户名
+// tty_type: 终端类型（pts/tty/console/display/other）
+// remote_class: 来源分类（local/loopback/private/public/hostname/display）
+func (m *MetricFactory) NewSessionsActive() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sessions_active",
+		Help: "Number of active login sessions from utmp by user, tty type and remote host class",
+	}, []string{"user", "tty_type", "remote_class"})
+	m.reg.MustRegister(gv)
+	return gv
+}
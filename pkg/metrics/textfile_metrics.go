@@ -0,0 +1,29 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// NewTextfileScrapeError 创建「textfile 解析错误」指标
+// 指标类型：Gauge - 文件解析失败为 1，成功为 0
+// 标签说明：
+// file: .prom 文件完整路径
+func (m *MetricFactory) NewTextfileScrapeError() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "textfile_scrape_error",
+		Help: "1 if there was an error opening or parsing the textfile, 0 otherwise",
+	}, []string{"file"})
+	m.reg.MustRegister(gv)
+	return gv
+}
+
+// NewTextfileMtimeSeconds 创建「textfile 修改时间」指标
+// 指标类型：Gauge - 文件 mtime 的 Unix 时间戳（秒），用于发现长时间未更新的文件
+// 标签说明：
+// file: .prom 文件完整路径
+func (m *MetricFactory) NewTextfileMtimeSeconds() *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "textfile_mtime_seconds",
+		Help: "Unixtime mtime of textfiles successfully read",
+	}, []string{"file"})
+	m.reg.MustRegister(gv)
+	return gv
+}
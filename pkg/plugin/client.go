@@ -0,0 +1,160 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrClosed 插件的 stdout 已关闭（通常是进程已退出）
+var ErrClosed = errors.New("plugin connection closed")
+
+// Client agent 侧的协议客户端，请求按顺序发送，同一时刻只有一个请求未完成
+type Client struct {
+	mu     sync.Mutex // 串行化请求
+	w      io.Writer
+	nextID uint64
+
+	responses chan Response
+	done      chan struct{} // 读协程退出后关闭
+	readErr   error         // done 关闭后可读
+}
+
+// NewClient 从 r 读取响应、向 w 写入请求，并启动读协程
+func NewClient(r io.Reader, w io.Writer) *Client {
+	c := &Client{
+		w:         w,
+		responses: make(chan Response, 8),
+		done:      make(chan struct{}),
+	}
+	go c.readLoop(r)
+	return c
+}
+
+// readLoop 逐行解析响应；读取失败或 r 关闭后结束
+func (c *Client) readLoop(r io.Reader) {
+	defer close(c.done)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		var resp Response
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			c.readErr = fmt.Errorf("decode plugin response: %w", err)
+			return
+		}
+		select {
+		case c.responses <- resp:
+		default:
+			// 没有请求在等待（超时后迟到的响应），丢弃最旧的一条
+			select {
+			case <-c.responses:
+			default:
+			}
+			c.responses <- resp
+		}
+	}
+	c.readErr = scanner.Err()
+}
+
+// Handshake 协商协议版本
+func (c *Client) Handshake(ctx context.Context) error {
+	resp, err := c.call(ctx, Request{Type: TypeHandshake, ProtocolVersion: ProtocolVersion})
+	if err != nil {
+		return err
+	}
+	if resp.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("plugin protocol version %d is not supported (agent speaks %d)", resp.ProtocolVersion, ProtocolVersion)
+	}
+	return nil
+}
+
+// Describe 获取插件名称、版本和声明的指标
+func (c *Client) Describe(ctx context.Context) (Description, error) {
+	resp, err := c.call(ctx, Request{Type: TypeDescribe})
+	if err != nil {
+		return Description{}, err
+	}
+	return Description{Name: resp.Name, Version: resp.Version, Metrics: resp.Metrics}, nil
+}
+
+// Collect 请求一次采集，返回 Prometheus 文本格式的指标
+func (c *Client) Collect(ctx context.Context) (string, error) {
+	req := Request{Type: TypeCollect}
+	if deadline, ok := ctx.Deadline(); ok {
+		req.TimeoutMillis = time.Until(deadline).Milliseconds()
+	}
+	resp, err := c.call(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// Shutdown 通知插件退出
+func (c *Client) Shutdown(ctx context.Context) error {
+	_, err := c.call(ctx, Request{Type: TypeShutdown})
+	return err
+}
+
+// call 发送请求并等待相同 id 的响应，之前超时请求的迟到响应直接丢弃
+func (c *Client) call(ctx context.Context, req Request) (Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	req.ID = c.nextID
+	line, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, fmt.Errorf("encode %s request: %w", req.Type, err)
+	}
+	if _, err := c.w.Write(append(line, '\n')); err != nil {
+		// 写 stdin 失败说明插件已退出或关闭了 stdin
+		return Response{}, fmt.Errorf("send %s request: %w: %w", req.Type, ErrClosed, err)
+	}
+
+	for {
+		select {
+		case resp := <-c.responses:
+			if resp.ID == req.ID {
+				return checkResponse(req, resp)
+			}
+		case <-c.done:
+			// 插件回复后立即退出（如 shutdown）时，响应可能仍在缓冲区中
+			if resp, ok := c.buffered(req.ID); ok {
+				return checkResponse(req, resp)
+			}
+			if c.readErr != nil {
+				return Response{}, fmt.Errorf("%w: %w", ErrClosed, c.readErr)
+			}
+			return Response{}, ErrClosed
+		case <-ctx.Done():
+			return Response{}, fmt.Errorf("wait for %s response: %w", req.Type, ctx.Err())
+		}
+	}
+}
+
+// buffered 在缓冲区中查找指定 id 的响应（不阻塞）
+func (c *Client) buffered(id uint64) (Response, bool) {
+	for {
+		select {
+		case resp := <-c.responses:
+			if resp.ID == id {
+				return resp, true
+			}
+		default:
+			return Response{}, false
+		}
+	}
+}
+
+func checkResponse(req Request, resp Response) (Response, error) {
+	if resp.Error != "" {
+		return resp, fmt.Errorf("plugin %s failed: %s", req.Type, resp.Error)
+	}
+	return resp, nil
+}
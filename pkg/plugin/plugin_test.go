@@ -0,0 +1,93 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type echoPlugin struct {
+	delay time.Duration
+	err   error
+}
+
+func (p echoPlugin) Describe() Description {
+	return Description{Name: "echo", Version: "1.2.3", Metrics: []MetricInfo{{Name: "echo_up", Type: "gauge"}}}
+}
+
+// Collect 故意忽略 ctx，模拟不遵守 timeout_ms 的插件
+func (p echoPlugin) Collect(context.Context) (string, error) {
+	time.Sleep(p.delay)
+	if p.err != nil {
+		return "", p.err
+	}
+	return "echo_up 1\n", nil
+}
+
+// servePipe 用两条内存管道连接 Client 和 Serve
+func servePipe(t *testing.T, p Plugin) (*Client, <-chan error) {
+	t.Helper()
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		err := Serve(context.Background(), p, reqR, respW)
+		// 模拟插件进程退出：两端管道都关闭
+		reqR.Close()
+		respW.Close()
+		served <- err
+	}()
+	t.Cleanup(func() { reqW.Close() })
+	return NewClient(respR, reqW), served
+}
+
+func TestClientServe(t *testing.T) {
+	c, served := servePipe(t, echoPlugin{})
+	ctx := context.Background()
+
+	if err := c.Handshake(ctx); err != nil {
+		t.Fatalf("handshake: %v", err)
+	}
+	desc, err := c.Describe(ctx)
+	if err != nil {
+		t.Fatalf("describe: %v", err)
+	}
+	if desc.Name != "echo" || desc.Version != "1.2.3" || !desc.Declares("echo_up") || desc.Declares("other") {
+		t.Errorf("describe = %+v", desc)
+	}
+	text, err := c.Collect(ctx)
+	if err != nil || text != "echo_up 1\n" {
+		t.Fatalf("collect = %q, %v", text, err)
+	}
+	if err := c.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if err := <-served; err != nil {
+		t.Fatalf("serve returned %v after shutdown", err)
+	}
+	if _, err := c.Collect(ctx); !errors.Is(err, ErrClosed) {
+		t.Errorf("collect after shutdown: %v, want ErrClosed", err)
+	}
+}
+
+func TestClientCollectErrors(t *testing.T) {
+	c, _ := servePipe(t, echoPlugin{err: errors.New("backend down")})
+	if _, err := c.Collect(context.Background()); err == nil || !strings.Contains(err.Error(), "backend down") {
+		t.Errorf("collect error = %v, want plugin error", err)
+	}
+
+	// 超时后迟到的响应不能被当作下一个请求的响应
+	slow, _ := servePipe(t, echoPlugin{delay: 100 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := slow.Collect(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("collect with short timeout: %v, want deadline exceeded", err)
+	}
+	desc, err := slow.Describe(context.Background())
+	if err != nil || desc.Name != "echo" {
+		t.Errorf("describe after timed out collect = %+v, %v", desc, err)
+	}
+}
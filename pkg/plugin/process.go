@@ -0,0 +1,118 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/agent-collector/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Process 由 agent 启动的插件进程
+type Process struct {
+	*Client
+	path   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	exited chan struct{}
+	err    error // exited 关闭后可读
+}
+
+// Start 启动插件进程并建立协议连接，插件的 stderr 按行写入日志
+// stdout/stderr 使用 os.Pipe 直接交给子进程，进程退出后仍能读完管道中剩余的响应
+func Start(path string) (*Process, error) {
+	cmd := exec.Command(path)
+	cmd.Dir = filepath.Dir(path)
+	setProcessGroup(cmd)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s stdin: %w", path, err)
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s stdout: %w", path, err)
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		stdoutR.Close()
+		stdoutW.Close()
+		return nil, fmt.Errorf("plugin %s stderr: %w", path, err)
+	}
+	cmd.Stdout, cmd.Stderr = stdoutW, stderrW
+
+	err = cmd.Start()
+	// 子进程已继承写端，父进程关闭自己的副本，子进程退出后读端才能读到 EOF
+	stdoutW.Close()
+	stderrW.Close()
+	if err != nil {
+		stdoutR.Close()
+		stderrR.Close()
+		return nil, fmt.Errorf("start plugin %s: %w", path, err)
+	}
+
+	p := &Process{
+		Client: NewClient(stdoutR, stdin),
+		path:   path,
+		cmd:    cmd,
+		stdin:  stdin,
+		exited: make(chan struct{}),
+	}
+	go logStderr(path, stderrR)
+	go func() {
+		<-p.Client.done
+		stdoutR.Close()
+	}()
+	go func() {
+		p.err = cmd.Wait()
+		close(p.exited)
+	}()
+	return p, nil
+}
+
+// Pid 插件进程号
+func (p *Process) Pid() int { return p.cmd.Process.Pid }
+
+// Exited 进程退出后关闭
+func (p *Process) Exited() <-chan struct{} { return p.exited }
+
+// Err 进程退出状态，需在 Exited 关闭后调用
+func (p *Process) Err() error { return p.err }
+
+// Kill 杀掉插件进程（unix 上连同其进程组）
+func (p *Process) Kill() error { return killProcess(p.cmd) }
+
+// Stop 发送 shutdown 并关闭 stdin，等待插件在 timeout 内退出，超时后杀掉
+func (p *Process) Stop(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil && !errors.Is(err, ErrClosed) {
+		logger.Warn("plugin did not acknowledge shutdown", zap.String("plugin", p.path), zap.Error(err))
+	}
+	_ = p.stdin.Close()
+
+	select {
+	case <-p.exited:
+		return nil
+	case <-ctx.Done():
+	}
+	_ = p.Kill()
+	<-p.exited
+	return fmt.Errorf("plugin %s did not exit within %s, killed", p.path, timeout)
+}
+
+// logStderr 将插件 stderr 按行写入日志
+func logStderr(path string, r io.ReadCloser) {
+	defer r.Close()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		logger.Info("plugin stderr", zap.String("plugin", path), zap.String("line", scanner.Text()))
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package plugin
+
+import "os/exec"
+
+// setProcessGroup 非 unix 平台不支持进程组
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcess 非 unix 平台只杀掉插件进程本身
+func killProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,81 @@
+// Package plugin 进程外采集器插件协议
+//
+// 插件是插件目录中的任意可执行文件（可以用任何语言编写），由 agent 启动并监管。
+// agent 通过插件的 stdin 发送请求、从 stdout 读取响应，每条消息是一行 JSON（以 '\n' 结尾）；
+// 插件的 stderr 会被 agent 按行写入日志。每个请求带递增的 id，插件按同样的 id 回复，同一时刻只有一个请求未完成。
+//
+// 消息流程：
+//
+//	agent -> {"id":1,"type":"handshake","protocol_version":1}
+//	plugin <- {"id":1,"type":"handshake","protocol_version":1}
+//	agent -> {"id":2,"type":"describe"}
+//	plugin <- {"id":2,"type":"describe","name":"redis","version":"1.0.0","metrics":[{"name":"redis_up","help":"...","type":"gauge"}]}
+//	agent -> {"id":3,"type":"collect","timeout_ms":10000}
+//	plugin <- {"id":3,"type":"collect","text":"# TYPE redis_up gauge\nredis_up 1\n"}
+//	agent -> {"id":4,"type":"shutdown"}
+//	plugin <- {"id":4,"type":"shutdown"}（随后退出）
+//
+// 任一请求失败时回复 {"id":N,"type":"<请求类型>","error":"..."}。collect 的 text 为 Prometheus 文本格式（不支持时间戳），
+// describe 中声明了 metrics 时，agent 只接收其中列出的指标族。Go 编写的插件可以直接使用 Serve。
+package plugin
+
+// ProtocolVersion 当前协议版本，握手时双方版本不一致则拒绝该插件
+const ProtocolVersion = 1
+
+// 消息类型
+const (
+	TypeHandshake = "handshake"
+	TypeDescribe  = "describe"
+	TypeCollect   = "collect"
+	TypeShutdown  = "shutdown"
+)
+
+// maxMessageSize 单条消息的最大长度（collect 返回的指标文本可能较大）
+const maxMessageSize = 16 << 20
+
+// Request agent 发给插件的请求
+type Request struct {
+	ID              uint64 `json:"id"`
+	Type            string `json:"type"`
+	ProtocolVersion int    `json:"protocol_version,omitempty"` // handshake：agent 使用的协议版本
+	TimeoutMillis   int64  `json:"timeout_ms,omitempty"`       // collect：agent 等待响应的最长时间
+}
+
+// Response 插件的响应
+type Response struct {
+	ID              uint64       `json:"id"`
+	Type            string       `json:"type"`
+	Error           string       `json:"error,omitempty"`
+	ProtocolVersion int          `json:"protocol_version,omitempty"` // handshake
+	Name            string       `json:"name,omitempty"`             // describe
+	Version         string       `json:"version,omitempty"`          // describe
+	Metrics         []MetricInfo `json:"metrics,omitempty"`          // describe
+	Text            string       `json:"text,omitempty"`             // collect：Prometheus 文本格式
+}
+
+// MetricInfo describe 中声明的指标族
+type MetricInfo struct {
+	Name string `json:"name"`
+	Help string `json:"help,omitempty"`
+	Type string `json:"type,omitempty"` // counter/gauge/histogram/summary/untyped
+}
+
+// Description 插件自我描述
+type Description struct {
+	Name    string
+	Version string
+	Metrics []MetricInfo
+}
+
+// Declares 插件是否声明了该指标族；没有声明任何指标时不做限制
+func (d Description) Declares(name string) bool {
+	if len(d.Metrics) == 0 {
+		return true
+	}
+	for _, m := range d.Metrics {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Plugin Go 编写的插件需要实现的接口，配合 Serve 使用
+type Plugin interface {
+	Describe() Description
+	Collect(ctx context.Context) (string, error) // 返回 Prometheus 文本格式的指标
+}
+
+// Serve 插件侧的协议循环：从 r 读取请求、向 w 写入响应，收到 shutdown 或 r 关闭后返回
+// 典型用法：plugin.Serve(context.Background(), myPlugin{}, os.Stdin, os.Stdout)
+func Serve(ctx context.Context, p Plugin, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			return fmt.Errorf("decode request: %w", err)
+		}
+		resp := handle(ctx, p, req)
+		if err := enc.Encode(resp); err != nil {
+			return fmt.Errorf("send %s response: %w", req.Type, err)
+		}
+		if req.Type == TypeShutdown {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// handle 处理单个请求
+func handle(ctx context.Context, p Plugin, req Request) Response {
+	resp := Response{ID: req.ID, Type: req.Type}
+	switch req.Type {
+	case TypeHandshake:
+		resp.ProtocolVersion = ProtocolVersion
+		if req.ProtocolVersion != ProtocolVersion {
+			resp.Error = fmt.Sprintf("unsupported protocol version %d", req.ProtocolVersion)
+		}
+	case TypeDescribe:
+		d := p.Describe()
+		resp.Name, resp.Version, resp.Metrics = d.Name, d.Version, d.Metrics
+	case TypeCollect:
+		cctx := ctx
+		if req.TimeoutMillis > 0 {
+			var cancel context.CancelFunc
+			cctx, cancel = context.WithTimeout(ctx, time.Duration(req.TimeoutMillis)*time.Millisecond)
+			defer cancel()
+		}
+		text, err := p.Collect(cctx)
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		resp.Text = text
+	case TypeShutdown:
+	default:
+		resp.Error = fmt.Sprintf("unknown request type %q", req.Type)
+	}
+	return resp
+}
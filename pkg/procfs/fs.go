@@ -0,0 +1,62 @@
+package procfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing/fstest"
+)
+
+// FS 采集器读取宿主机文件的抽象，路径均为绝对路径（如 ProcPath("stat") 的结果）
+// 生产环境使用 OSFS；测试使用 DirFS（fixture 目录）或 MapFS（内存），不再依赖运行机器上的 /proc
+type FS interface {
+	Open(name string) (fs.File, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Stat(name string) (fs.FileInfo, error)
+}
+
+// OSFS 直接访问本机文件系统
+type OSFS struct{}
+
+func (OSFS) Open(name string) (fs.File, error)          { return os.Open(name) }
+func (OSFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (OSFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+
+// DirFS 以 Root 目录作为根的文件系统：Open("/proc/stat") 读取 Root/proc/stat
+// 用于加载 testdata 下采集自真实主机的 /proc、/sys fixture
+type DirFS struct {
+	Root string
+}
+
+func (d DirFS) Open(name string) (fs.File, error)          { return os.Open(d.join(name)) }
+func (d DirFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(d.join(name)) }
+func (d DirFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(d.join(name)) }
+
+func (d DirFS) join(name string) string {
+	return filepath.Join(d.Root, filepath.FromSlash(name))
+}
+
+// MapFS 内存文件系统，键为绝对路径，如 MapFS{"/proc/loadavg": {Data: []byte("0.1 0.2 0.3 1/100 42\n")}}
+type MapFS map[string]*fstest.MapFile
+
+func (m MapFS) Open(name string) (fs.File, error)          { return m.fs().Open(unroot(name)) }
+func (m MapFS) ReadDir(name string) ([]fs.DirEntry, error) { return m.fs().ReadDir(unroot(name)) }
+func (m MapFS) Stat(name string) (fs.FileInfo, error)      { return m.fs().Stat(unroot(name)) }
+
+func (m MapFS) fs() fstest.MapFS {
+	out := make(fstest.MapFS, len(m))
+	for k, v := range m {
+		out[unroot(k)] = v
+	}
+	return out
+}
+
+// unroot 将绝对路径转换为 io/fs 要求的相对路径（"/" -> "."）
+func unroot(name string) string {
+	name = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(name)), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
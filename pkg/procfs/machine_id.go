@@ -0,0 +1,24 @@
+package procfs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// MachineID 读取宿主机的 machine-id（/etc/machine-id，不存在时读 /var/lib/dbus/machine-id），路径相对于 rootfs 挂载点
+func MachineID(fsys FS) (string, error) {
+	var errs []error
+	for _, path := range []string{RootPath("etc", "machine-id"), RootPath("var", "lib", "dbus", "machine-id")} {
+		var buf bytes.Buffer
+		if err := readInto(fsys, path, &buf); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if id := bytes.TrimSpace(buf.Bytes()); len(id) > 0 {
+			return string(id), nil
+		}
+		errs = append(errs, fmt.Errorf("%s is empty", path))
+	}
+	return "", fmt.Errorf("read machine-id: %w", errors.Join(errs...))
+}
@@ -0,0 +1,112 @@
+package procfs
+
+// 以下解析工具直接在 []byte 上工作，不产生内存分配，供各采集器解析快照内容
+
+// NextLine 返回第一行（不含换行符）和剩余内容
+func NextLine(data []byte) (line, rest []byte) {
+	for i, b := range data {
+		if b == '\n' {
+			return data[:i], data[i+1:]
+		}
+	}
+	return data, nil
+}
+
+// NextField 跳过前导空白，返回下一个以空白分隔的字段和剩余内容；没有字段时 field 为空
+func NextField(data []byte) (field, rest []byte) {
+	i := 0
+	for i < len(data) && isSpace(data[i]) {
+		i++
+	}
+	j := i
+	for j < len(data) && !isSpace(data[j]) {
+		j++
+	}
+	return data[i:j], data[j:]
+}
+
+// ParseUint 解析十进制无符号整数，非法或溢出时 ok 为 false
+func ParseUint(b []byte) (v uint64, ok bool) {
+	if len(b) == 0 {
+		return 0, false
+	}
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		d := uint64(c - '0')
+		if v > (1<<64-1-d)/10 {
+			return 0, false
+		}
+		v = v*10 + d
+	}
+	return v, true
+}
+
+// HasPrefix 等同于 bytes.HasPrefix(b, []byte(prefix))，避免转换
+func HasPrefix(b []byte, prefix string) bool {
+	return len(b) >= len(prefix) && string(b[:len(prefix)]) == prefix
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// ParseDecimal 解析 /proc/loadavg 这类不带指数的十进制小数（如 "0.52"）
+func ParseDecimal(b []byte) (float64, bool) {
+	intPart, fracPart := b, []byte(nil)
+	for i, c := range b {
+		if c == '.' {
+			intPart, fracPart = b[:i], b[i+1:]
+			break
+		}
+	}
+	v, ok := ParseUint(intPart)
+	if !ok {
+		return 0, false
+	}
+	result := float64(v)
+	scale := 1.0
+	for _, c := range fracPart {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		scale /= 10
+		result += float64(c-'0') * scale
+	}
+	return result, true
+}
+
+// CountCPUList 统计 CPU 列表格式（如 "0-3,8,10-11"）中的 CPU 个数
+func CountCPUList(b []byte) (int, bool) {
+	count := 0
+	for len(b) > 0 && !isSpace(b[0]) {
+		end := len(b)
+		for i, c := range b {
+			if c == ',' || isSpace(c) {
+				end = i
+				break
+			}
+		}
+		item := b[:end]
+		if end < len(b) && b[end] == ',' {
+			end++
+		}
+		b = b[end:]
+
+		lo, hi := item, item
+		for i, c := range item {
+			if c == '-' {
+				lo, hi = item[:i], item[i+1:]
+				break
+			}
+		}
+		start, ok1 := ParseUint(lo)
+		stop, ok2 := ParseUint(hi)
+		if !ok1 || !ok2 || stop < start {
+			return 0, false
+		}
+		count += int(stop-start) + 1
+	}
+	return count, count > 0
+}
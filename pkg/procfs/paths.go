@@ -0,0 +1,80 @@
+package procfs
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// 默认挂载点；以 DaemonSet 方式部署时通常把宿主机的 /proc、/sys、/ 挂载到 /host/proc、/host/sys、/host
+const (
+	DefaultProcFS = "/proc"
+	DefaultSysFS  = "/sys"
+	DefaultRootFS = "/"
+)
+
+var (
+	pathsMu sync.RWMutex
+	procFS  = DefaultProcFS
+	sysFS   = DefaultSysFS
+	rootFS  = DefaultRootFS
+)
+
+// Configure 设置 procfs/sysfs/rootfs 挂载点（启动时调用一次），空字符串保持默认值
+// 同时设置 gopsutil 使用的 HOST_PROC/HOST_SYS/HOST_ROOT 等环境变量，保证两边看到的是同一个宿主机视图
+func Configure(proc, sys, root string) {
+	pathsMu.Lock()
+	defer pathsMu.Unlock()
+	procFS = defaultPath(proc, DefaultProcFS)
+	sysFS = defaultPath(sys, DefaultSysFS)
+	rootFS = defaultPath(root, DefaultRootFS)
+
+	setHostEnv("HOST_PROC", procFS, DefaultProcFS)
+	setHostEnv("HOST_SYS", sysFS, DefaultSysFS)
+	setHostEnv("HOST_ROOT", rootFS, DefaultRootFS)
+	if rootFS != DefaultRootFS {
+		setHostEnv("HOST_ETC", filepath.Join(rootFS, "etc"), "/etc")
+		setHostEnv("HOST_VAR", filepath.Join(rootFS, "var"), "/var")
+		setHostEnv("HOST_RUN", filepath.Join(rootFS, "run"), "/run")
+		setHostEnv("HOST_DEV", filepath.Join(rootFS, "dev"), "/dev")
+	}
+}
+
+// ProcPath 拼接 procfs 下的路径，如 ProcPath("stat") -> /host/proc/stat
+func ProcPath(elem ...string) string {
+	pathsMu.RLock()
+	defer pathsMu.RUnlock()
+	return filepath.Join(append([]string{procFS}, elem...)...)
+}
+
+// SysPath 拼接 sysfs 下的路径，如 SysPath("devices/system/cpu") -> /host/sys/devices/system/cpu
+func SysPath(elem ...string) string {
+	pathsMu.RLock()
+	defer pathsMu.RUnlock()
+	return filepath.Join(append([]string{sysFS}, elem...)...)
+}
+
+// RootPath 拼接宿主机根文件系统下的路径（用于 /var/run/utmp 这类不在 procfs/sysfs 中的文件）
+func RootPath(elem ...string) string {
+	pathsMu.RLock()
+	defer pathsMu.RUnlock()
+	return filepath.Join(append([]string{rootFS}, elem...)...)
+}
+
+func defaultPath(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// setHostEnv 仅在路径不是默认值时设置环境变量，已由外部显式设置的变量不覆盖
+func setHostEnv(key, value, def string) {
+	if value == def {
+		return
+	}
+	if _, ok := os.LookupEnv(key); ok {
+		return
+	}
+	_ = os.Setenv(key, value)
+}
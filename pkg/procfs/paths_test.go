@@ -0,0 +1,64 @@
+package procfs_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/agent-collector/pkg/procfs"
+)
+
+func TestConfigurePaths(t *testing.T) {
+	// t.Setenv 在测试结束后恢复环境变量；先清空以验证 Configure 的设置逻辑
+	for _, key := range []string{"HOST_PROC", "HOST_SYS", "HOST_ROOT", "HOST_ETC", "HOST_VAR", "HOST_RUN", "HOST_DEV"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Setenv("HOST_SYS", "/custom/sys")
+	defer procfs.Configure("", "", "")
+
+	procfs.Configure("/host/proc", "/host/sys", "/host")
+	if got := procfs.ProcPath("stat"); got != "/host/proc/stat" {
+		t.Errorf("ProcPath = %q", got)
+	}
+	if got := procfs.SysPath("devices", "system", "cpu"); got != "/host/sys/devices/system/cpu" {
+		t.Errorf("SysPath = %q", got)
+	}
+	if got := procfs.RootPath("/var/run/utmp"); got != "/host/var/run/utmp" {
+		t.Errorf("RootPath = %q", got)
+	}
+	if got := os.Getenv("HOST_PROC"); got != "/host/proc" {
+		t.Errorf("HOST_PROC = %q", got)
+	}
+	if got := os.Getenv("HOST_SYS"); got != "/custom/sys" {
+		t.Errorf("explicit HOST_SYS should not be overridden, got %q", got)
+	}
+	if got := os.Getenv("HOST_ETC"); got != "/host/etc" {
+		t.Errorf("HOST_ETC = %q", got)
+	}
+
+	procfs.Configure("", "", "")
+	if got := procfs.ProcPath("stat"); got != "/proc/stat" {
+		t.Errorf("default ProcPath = %q", got)
+	}
+}
+
+func TestMachineID(t *testing.T) {
+	id, err := procfs.MachineID(procfs.MapFS{
+		"/etc/machine-id":          {Data: []byte("4c4c4544004d3510\n")},
+		"/var/lib/dbus/machine-id": {Data: []byte("dbus\n")},
+	})
+	if err != nil || id != "4c4c4544004d3510" {
+		t.Errorf("MachineID = %q, %v", id, err)
+	}
+	// /etc/machine-id 为空（如镜像模板）时使用 dbus 的 machine-id
+	id, err = procfs.MachineID(procfs.MapFS{
+		"/etc/machine-id":          {Data: []byte("\n")},
+		"/var/lib/dbus/machine-id": {Data: []byte("dbus\n")},
+	})
+	if err != nil || id != "dbus" {
+		t.Errorf("MachineID fallback = %q, %v", id, err)
+	}
+	if _, err := procfs.MachineID(procfs.MapFS{}); err == nil {
+		t.Error("MachineID without machine-id files succeeded")
+	}
+}
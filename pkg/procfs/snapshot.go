@@ -0,0 +1,141 @@
+package procfs
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+)
+
+// bufPool 读取 procfs/sysfs 文件用的缓冲区池，避免每个采集周期重新分配
+// procfs 文件 stat 大小为 0，os.ReadFile 每次都要从小缓冲区开始扩容，复用后只在首次扩容
+var bufPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// snapshotKey context 中保存 Snapshot 的键
+type snapshotKey struct{}
+
+// fileEntry 单个文件在本周期的读取结果
+type fileEntry struct {
+	once    sync.Once
+	buf     *bytes.Buffer
+	err     error
+	readers int // 正在 ReadFile 中的调用数（受 Snapshot.mu 保护），大于 0 时 Release 不能回收 buf
+}
+
+// Snapshot 单个采集周期的 procfs/sysfs 文件快照
+// 由 AgentImpl.CollectAll 在每轮采集开始时创建，通过 context 传给各采集器：
+// 同一个文件在一个周期内最多读取一次，所有采集器看到的是同一份内容
+// ReadFile 返回的字节切片只在本周期内有效（Release 后缓冲区会被复用），采集器不能跨周期持有
+type Snapshot struct {
+	fs    FS
+	taken time.Time
+
+	mu      sync.Mutex
+	files   map[string]*fileEntry
+	release bool
+}
+
+// NewSnapshot 创建新的采集周期快照，所有文件通过 fsys 读取
+func NewSnapshot(fsys FS) *Snapshot {
+	return &Snapshot{
+		fs:    fsys,
+		taken: time.Now(),
+		files: make(map[string]*fileEntry),
+	}
+}
+
+// Time 快照创建时间（本轮采集的时间基准）
+func (s *Snapshot) Time() time.Time { return s.taken }
+
+// ReadFile 读取文件内容，本周期内重复读取直接返回第一次的结果（包括错误）
+// 并发安全：不同文件可以并行读取，同一文件只会读取一次
+func (s *Snapshot) ReadFile(path string) ([]byte, error) {
+	s.mu.Lock()
+	if s.release {
+		// 快照已释放（采集器超出本周期仍在读取），退化为直接读取
+		s.mu.Unlock()
+		return ReadFile(context.Background(), s.fs, path, nil)
+	}
+	e, ok := s.files[path]
+	if !ok {
+		e = &fileEntry{}
+		s.files[path] = e
+	}
+	e.readers++
+	s.mu.Unlock()
+
+	e.once.Do(func() {
+		buf := bufPool.Get().(*bytes.Buffer)
+		buf.Reset()
+		e.err = readInto(s.fs, path, buf)
+		e.buf = buf
+	})
+	var data []byte
+	err := e.err
+	if err == nil {
+		data = e.buf.Bytes()
+	}
+
+	s.mu.Lock()
+	e.readers--
+	s.mu.Unlock()
+	return data, err
+}
+
+// Release 归还本周期使用的缓冲区，调用后之前返回的字节切片不能再使用
+// 与 Release 并发、尚未返回的 ReadFile 仍在使用的缓冲区不放回池中，由 GC 回收，这些调用拿到的切片保持有效
+func (s *Snapshot) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.release {
+		return
+	}
+	s.release = true
+	for _, e := range s.files {
+		if e.readers == 0 && e.buf != nil {
+			bufPool.Put(e.buf)
+			e.buf = nil
+		}
+	}
+	s.files = nil
+}
+
+// WithSnapshot 将快照放入 context
+func WithSnapshot(ctx context.Context, s *Snapshot) context.Context {
+	return context.WithValue(ctx, snapshotKey{}, s)
+}
+
+// FromContext 取出 context 中的快照，不存在时返回 nil
+func FromContext(ctx context.Context) *Snapshot {
+	s, _ := ctx.Value(snapshotKey{}).(*Snapshot)
+	return s
+}
+
+// ReadFile 优先从 context 中的快照读取；没有快照时（如采集器被单独调用）通过 fsys 直接读取文件
+// 直接读取时使用调用方提供的缓冲区，buf 为 nil 时分配新的缓冲区
+func ReadFile(ctx context.Context, fsys FS, path string, buf *bytes.Buffer) ([]byte, error) {
+	if s := FromContext(ctx); s != nil {
+		return s.ReadFile(path)
+	}
+	if buf == nil {
+		buf = new(bytes.Buffer)
+	}
+	buf.Reset()
+	if err := readInto(fsys, path, buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readInto 将整个文件读入缓冲区
+func readInto(fsys FS, path string, buf *bytes.Buffer) error {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = buf.ReadFrom(f)
+	return err
+}
@@ -0,0 +1,175 @@
+package procfs_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/agent-collector/pkg/procfs"
+)
+
+func TestSnapshotReadsFileOncePerCycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stat")
+	if err := os.WriteFile(path, []byte("cpu 1 2 3 4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := procfs.NewSnapshot(procfs.OSFS{})
+	ctx := procfs.WithSnapshot(context.Background(), snapshot)
+	first, err := procfs.ReadFile(ctx, procfs.OSFS{}, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("cpu 5 6 7 8\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	second, err := procfs.ReadFile(ctx, procfs.OSFS{}, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != "cpu 1 2 3 4\n" || string(second) != string(first) {
+		t.Fatalf("expected consistent view within a cycle, got %q and %q", first, second)
+	}
+	snapshot.Release()
+
+	next := procfs.NewSnapshot(procfs.OSFS{})
+	defer next.Release()
+	third, err := next.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(third) != "cpu 5 6 7 8\n" {
+		t.Fatalf("expected new cycle to re-read file, got %q", third)
+	}
+
+	if _, err := next.ReadFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+// gatedFS 读取在 Open 中阻塞到 gate 关闭，用来构造「读取进行中时快照被释放」的时序
+type gatedFS struct {
+	procfs.MapFS
+	entered chan struct{}
+	gate    chan struct{}
+}
+
+func (g gatedFS) Open(name string) (fs.File, error) {
+	g.entered <- struct{}{}
+	<-g.gate
+	return g.MapFS.Open(name)
+}
+
+// TestSnapshotReadRacesRelease 采集器超时后仍在读取时本周期被 Release：
+// 不能出现数据竞争（需配合 -race 运行），进行中的读取拿到的内容也不能被回收到池中的缓冲区污染
+func TestSnapshotReadRacesRelease(t *testing.T) {
+	const readers = 4
+	content := strings.Repeat("cpu0 1 2 3 4\n", 64)
+	fsys := gatedFS{
+		MapFS:   procfs.MapFS{"/proc/stat": {Data: []byte(content)}},
+		entered: make(chan struct{}, readers),
+		gate:    make(chan struct{}),
+	}
+	snapshot := procfs.NewSnapshot(fsys)
+
+	results := make(chan []byte, readers)
+	var wg sync.WaitGroup
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := snapshot.ReadFile("/proc/stat")
+			if err != nil {
+				t.Errorf("read: %v", err)
+			}
+			results <- data
+		}()
+	}
+	<-fsys.entered // 其中一个读取已进入 Open，其余等待同一个 once
+	snapshot.Release()
+
+	// 下一个周期从池中取缓冲区并写入其他内容，不能影响上一周期仍在进行的读取
+	close(fsys.gate)
+	next := procfs.NewSnapshot(procfs.MapFS{"/proc/stat": {Data: []byte(strings.Repeat("x", len(content)))}})
+	if _, err := next.ReadFile("/proc/stat"); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	next.Release()
+	close(results)
+	for data := range results {
+		if string(data) != content {
+			t.Errorf("read racing Release returned %d bytes of wrong content", len(data))
+		}
+	}
+}
+
+func TestParseHelpersDoNotAllocate(t *testing.T) {
+	data := []byte("cpu  10 20 30 40 50\ncpu0 1 2 3 4\nintr 123\n")
+	var sum uint64
+	allocs := testing.AllocsPerRun(100, func() {
+		rest := data
+		for len(rest) > 0 {
+			var line []byte
+			line, rest = procfs.NextLine(rest)
+			if !procfs.HasPrefix(line, "cpu") {
+				continue
+			}
+			_, fields := procfs.NextField(line)
+			for {
+				var f []byte
+				f, fields = procfs.NextField(fields)
+				if len(f) == 0 {
+					break
+				}
+				v, _ := procfs.ParseUint(f)
+				sum += v
+			}
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("expected 0 allocations, got %v", allocs)
+	}
+	if sum == 0 {
+		t.Fatal("expected parsed values")
+	}
+}
+
+func TestParseUint(t *testing.T) {
+	cases := map[string]struct {
+		v  uint64
+		ok bool
+	}{
+		"0":                    {0, true},
+		"18446744073709551615": {18446744073709551615, true},
+		"18446744073709551616": {0, false},
+		"12a":                  {0, false},
+		"":                     {0, false},
+	}
+	for in, want := range cases {
+		v, ok := procfs.ParseUint([]byte(in))
+		if v != want.v || ok != want.ok {
+			t.Errorf("ParseUint(%q) = %d, %v; want %d, %v", in, v, ok, want.v, want.ok)
+		}
+	}
+}
+
+func TestCountCPUList(t *testing.T) {
+	cases := map[string]int{
+		"0\n":           1,
+		"0-3\n":         4,
+		"0-3,8,10-11\n": 7,
+		"":              0,
+		"3-1":           0,
+	}
+	for in, want := range cases {
+		got, _ := procfs.CountCPUList([]byte(in))
+		if got != want {
+			t.Errorf("CountCPUList(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
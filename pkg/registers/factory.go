@@ -0,0 +1,116 @@
+package registers
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/agent-collector/pkg/config"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+)
+
+// Deps 构造采集器时可用的公共依赖
+type Deps struct {
+	MetricFactory metrics.MetricFactory
+	FS            procfs.FS
+}
+
+// Factory 按名称注册的采集器工厂
+// Name 同时是配置键：collectors.<name>.enable 控制是否启用，collectors.<name> 下的其余键解码到 NewConfig 返回的结构体
+type Factory struct {
+	Name           string                                      // 配置键，小写（viper 读取配置时会把键转为小写）
+	Description    string                                      // 命令行帮助中的说明
+	DefaultEnabled bool                                        // 配置中没有 collectors.<name>.enable 时是否启用
+	NewConfig      func() any                                  // 返回填好默认值的配置结构体指针，为 nil 表示没有配置项
+	New            func(cfg any, deps Deps) (Collector, error) // cfg 为 NewConfig 的返回值（已解码、已校验）
+}
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// RegisterFactory 注册采集器工厂，由各采集器在 init() 中调用；名称为空、缺少 New 或重复注册时 panic
+func RegisterFactory(f Factory) {
+	if f.Name == "" || f.New == nil {
+		panic(fmt.Sprintf("registers: invalid collector factory %q", f.Name))
+	}
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if _, dup := factories[f.Name]; dup {
+		panic(fmt.Sprintf("registers: collector factory %q registered twice", f.Name))
+	}
+	factories[f.Name] = f
+}
+
+// Factories 返回所有已注册的工厂（按名称排序）
+func Factories() []Factory {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	out := make([]Factory, 0, len(factories))
+	for _, f := range factories {
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// LookupFactory 按名称查找工厂
+func LookupFactory(name string) (Factory, bool) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	f, ok := factories[name]
+	return f, ok
+}
+
+// collectorSection collectors.<name> 中由注册器处理的公共配置项
+type collectorSection struct {
+	Enable   *bool `mapstructure:"enable"`
+	Required bool  `mapstructure:"required"`
+}
+
+func (f Factory) section(raw map[string]any) (collectorSection, error) {
+	var section collectorSection
+	if err := config.Decode(raw, &section); err != nil {
+		return section, fmt.Errorf("collectors.%s: %w", f.Name, err)
+	}
+	return section, nil
+}
+
+// Enabled 根据 collectors.<name> 配置判断是否启用，未配置 enable 时使用 DefaultEnabled
+func (f Factory) Enabled(raw map[string]any) (bool, error) {
+	section, err := f.section(raw)
+	if err != nil {
+		return false, err
+	}
+	if section.Enable == nil {
+		return f.DefaultEnabled, nil
+	}
+	return *section.Enable, nil
+}
+
+// Required 根据 collectors.<name>.required 判断是否为必需采集器（初始化失败时启动失败，默认 false）
+func (f Factory) Required(raw map[string]any) (bool, error) {
+	section, err := f.section(raw)
+	if err != nil {
+		return false, err
+	}
+	return section.Required, nil
+}
+
+// Build 将 collectors.<name> 配置解码到工厂的配置结构体并创建采集器
+func (f Factory) Build(raw map[string]any, deps Deps) (Collector, error) {
+	var cfg any
+	if f.NewConfig != nil {
+		cfg = f.NewConfig()
+		if err := config.Decode(raw, cfg); err != nil {
+			return nil, fmt.Errorf("collectors.%s: %w", f.Name, err)
+		}
+	}
+	c, err := f.New(cfg, deps)
+	if err != nil {
+		return nil, fmt.Errorf("create collector %s: %w", f.Name, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,138 @@
+package registers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/config"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// testFactoryConfig 测试工厂的配置结构体
+type testFactoryConfig struct {
+	Timeout time.Duration `mapstructure:"timeout"`
+	Targets []string      `mapstructure:"targets"`
+}
+
+func (c *testFactoryConfig) Validate() error {
+	if len(c.Targets) == 0 {
+		return errors.New("targets cannot be empty")
+	}
+	return nil
+}
+
+// 本包测试不导入 pkg/collector，工厂列表中只有这里注册的两个工厂
+func init() {
+	RegisterFactory(Factory{
+		Name:           "test-default-on",
+		DefaultEnabled: true,
+		New: func(cfg any, deps Deps) (Collector, error) {
+			return &fakeCollector{name: "default-on-collector"}, nil
+		},
+	})
+	RegisterFactory(Factory{
+		Name:      "test-configured",
+		NewConfig: func() any { return &testFactoryConfig{Timeout: time.Second} },
+		New: func(cfg any, deps Deps) (Collector, error) {
+			c := cfg.(*testFactoryConfig)
+			return &fakeCollector{name: "configured-collector", timeout: c.Timeout}, nil
+		},
+	})
+}
+
+func registerFromConfig(t *testing.T, collectors map[string]map[string]any) (*AgentImpl, []Collector, error) {
+	t.Helper()
+	cfg := config.NewDefaultConfig()
+	cfg.Monitor.Collectors = collectors
+	factory := *metrics.NewMetricFactory(metrics.NewPromRegistry(prometheus.NewRegistry()))
+	agent := NewRegistry(&cfg.Monitor, factory, procfs.OSFS{})
+	registered, err := RegisterCollectors(agent, cfg, factory, procfs.OSFS{})
+	return agent, registered, err
+}
+
+func TestRegisterCollectorsFromFactories(t *testing.T) {
+	agent, registered, err := registerFromConfig(t, map[string]map[string]any{
+		"test-configured": {"enable": "true", "required": "true", "timeout": "3s", "targets": "a,b"},
+		"sys":             {"enable": true}, // 没有对应工厂，只告警
+	})
+	if err != nil {
+		t.Fatalf("RegisterCollectors: %v", err)
+	}
+	var names []string
+	for _, c := range registered {
+		names = append(names, c.Name())
+	}
+	if got := strings.Join(names, ","); got != "configured-collector,default-on-collector" {
+		t.Fatalf("registered = %s, want configured-collector,default-on-collector", got)
+	}
+	if timeout := registered[0].(*fakeCollector).timeout; timeout != 3*time.Second {
+		t.Errorf("decoded timeout = %s, want 3s", timeout)
+	}
+	statuses := agent.Collectors()
+	if len(statuses) != 2 {
+		t.Fatalf("agent has %d collectors, want 2", len(statuses))
+	}
+	if !statuses[0].Required || statuses[1].Required {
+		t.Errorf("required = %v/%v, want only configured-collector required", statuses[0].Required, statuses[1].Required)
+	}
+}
+
+func TestRegisterCollectorsConfigErrors(t *testing.T) {
+	for name, collectors := range map[string]map[string]map[string]any{
+		"validation fails": {"test-configured": {"enable": true}},
+		"bad duration":     {"test-configured": {"enable": true, "timeout": "soon", "targets": "a"}},
+		"none enabled":     {"test-default-on": {"enable": false}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := registerFromConfig(t, collectors); err == nil {
+				t.Fatal("RegisterCollectors succeeded, want error")
+			}
+		})
+	}
+}
+
+// TestRegisterCollectorsSchedulesByFactoryName metrics.schedules 与 collectors 使用同一个名称（工厂名称），
+// 采集器名称仍然兼容，其他键只告警
+func TestRegisterCollectorsSchedulesByFactoryName(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Monitor.Collectors = map[string]map[string]any{
+		"test-configured": {"enable": true, "targets": "a"},
+	}
+	cfg.Monitor.Schedules = map[string]config.ScheduleConfig{
+		"test-configured":      {Interval: 2 * time.Minute, Timeout: 5 * time.Second},
+		"default-on-collector": {Interval: 3 * time.Minute},
+		"cpu":                  {Interval: time.Minute},
+	}
+	factory := *metrics.NewMetricFactory(metrics.NewPromRegistry(prometheus.NewRegistry()))
+	agent := NewRegistry(&cfg.Monitor, factory, procfs.OSFS{})
+	registered, err := RegisterCollectors(agent, cfg, factory, procfs.OSFS{})
+	if err != nil {
+		t.Fatalf("RegisterCollectors: %v", err)
+	}
+
+	want := map[string][2]string{
+		"configured-collector": {"2m0s", "5s"},
+		"default-on-collector": {"3m0s", "3m0s"},
+	}
+	for _, s := range agent.Collectors() {
+		if got := [2]string{s.Interval, s.Timeout}; got != want[s.Name] {
+			t.Errorf("%s: interval/timeout = %v, want %v", s.Name, got, want[s.Name])
+		}
+	}
+	if got := unknownSchedules(cfg.Monitor.Schedules, registered); len(got) != 1 || got[0] != "cpu" {
+		t.Errorf("unknown schedules = %v, want [cpu]", got)
+	}
+}
+
+func TestRegisterFactoryDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering a duplicate factory did not panic")
+		}
+	}()
+	RegisterFactory(Factory{Name: "test-default-on", New: func(any, Deps) (Collector, error) { return nil, nil }})
+}
@@ -0,0 +1,160 @@
+package registers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/agent-collector/pkg/config"
+)
+
+// CollectorState 采集器健康状态
+type CollectorState string
+
+const (
+	StateHealthy    CollectorState = "healthy"     // 最近一次采集成功
+	StateDegraded   CollectorState = "degraded"    // 连续失败，但未达到退避阈值，仍按正常间隔采集
+	StateBackingOff CollectorState = "backing_off" // 连续失败达到阈值，按指数退避延后下一次采集，成功后自动恢复
+	StateDisabled   CollectorState = "disabled"    // 连续失败达到 disable_after 次或通过管理接口停用后停止调度
+	StateInitFailed CollectorState = "init_failed" // Init 失败，不参与调度，后台重试 Init 成功后进入 healthy
+)
+
+// collectorStates 所有状态，用于导出 agent_collector_state 的全部标签组合
+var collectorStates = []CollectorState{StateHealthy, StateDegraded, StateBackingOff, StateDisabled, StateInitFailed}
+
+// collectorHealth 单个采集器的状态机
+//
+//	healthy --失败--> degraded --连续失败达到 threshold--> backing_off --连续失败达到 disable_after--> disabled
+//	   ^                 |                                   |
+//	   +------成功-------+-----------------成功--------------+
+//
+// 退避时间从 initial 开始每次失败翻倍，最大为 max；退避期间调度器不调用 Collect
+// Init 失败的采集器处于 init_failed，Init 重试成功后进入 healthy；停用期间 Init 成功则保持 disabled，重新启用后回到 healthy
+type collectorHealth struct {
+	cfg config.BackoffConfig
+
+	mu       sync.Mutex
+	state    CollectorState
+	failures int           // 连续失败次数
+	backoff  time.Duration // 当前退避时间
+	retryAt  time.Time     // 退避结束时间
+	lastErr  error
+
+	uninitialized bool // Init 尚未成功（停用期间也要记录，重新启用时据此回到 init_failed）
+
+	lastRun      time.Time     // 最近一次采集开始时间
+	lastDuration time.Duration // 最近一次采集耗时
+}
+
+func newCollectorHealth(cfg config.BackoffConfig) *collectorHealth {
+	return &collectorHealth{cfg: cfg, state: StateHealthy}
+}
+
+// ready 当前是否允许采集（退避中且未到重试时间、或已停用时返回 false）
+func (h *collectorHealth) ready(now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch h.state {
+	case StateDisabled, StateInitFailed:
+		return false
+	case StateBackingOff:
+		return !now.Before(h.retryAt)
+	default:
+		return true
+	}
+}
+
+// initFailed 记录一次 Init 失败，停用中的采集器保持 disabled
+func (h *collectorHealth) initFailed(err error) (from, to CollectorState) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	from = h.state
+	h.uninitialized, h.lastErr = true, err
+	if from != StateDisabled {
+		h.state = StateInitFailed
+	}
+	return from, h.state
+}
+
+// initialized 记录 Init 成功：init_failed 进入 healthy，停用中的采集器保持 disabled
+func (h *collectorHealth) initialized() (from, to CollectorState) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	from = h.state
+	h.uninitialized, h.lastErr = false, nil
+	if from == StateInitFailed {
+		h.state = StateHealthy
+	}
+	return from, h.state
+}
+
+// record 记录一次采集结果并返回状态变化（from == to 表示状态未变）
+// 已停用时不再改变状态：停用前已经开始的采集结束后不会把采集器重新置为 healthy
+func (h *collectorHealth) record(err error, now time.Time) (from, to CollectorState) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	from = h.state
+	if from == StateDisabled {
+		return from, from
+	}
+	if err == nil {
+		h.state, h.failures, h.backoff, h.lastErr = StateHealthy, 0, 0, nil
+		return from, h.state
+	}
+
+	h.failures++
+	h.lastErr = err
+	switch {
+	case h.cfg.DisableAfter > 0 && h.failures >= h.cfg.DisableAfter:
+		h.state = StateDisabled
+	case h.failures >= h.cfg.Threshold:
+		if h.backoff == 0 {
+			h.backoff = h.cfg.Initial
+		} else {
+			h.backoff = min(h.backoff*2, h.cfg.Max)
+		}
+		h.retryAt = now.Add(h.backoff)
+		h.state = StateBackingOff
+	default:
+		h.state = StateDegraded
+	}
+	return from, h.state
+}
+
+// observe 记录最近一次采集的开始时间和耗时
+func (h *collectorHealth) observe(start time.Time, d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastRun, h.lastDuration = start, d
+}
+
+// setEnabled 手动启用/停用：停用直接进入 disabled；启用时清空失败计数回到 healthy（Init 尚未成功时回到 init_failed）
+func (h *collectorHealth) setEnabled(enabled bool) (from, to CollectorState) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	from = h.state
+	if enabled {
+		if from == StateDisabled {
+			h.state, h.failures, h.backoff = StateHealthy, 0, 0
+			if h.uninitialized {
+				h.state = StateInitFailed
+			}
+		}
+	} else {
+		h.state = StateDisabled
+	}
+	return from, h.state
+}
+
+// currentBackoff 当前退避时间
+func (h *collectorHealth) currentBackoff() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.backoff
+}
+
+// snapshot 返回当前状态、连续失败次数和最近一次错误
+func (h *collectorHealth) snapshot() (CollectorState, int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.state, h.failures, h.lastErr
+}
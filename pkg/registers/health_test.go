@@ -0,0 +1,75 @@
+package registers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/config"
+)
+
+func TestCollectorHealthTransitions(t *testing.T) {
+	h := newCollectorHealth(config.BackoffConfig{Threshold: 2, Initial: time.Second, Max: 3 * time.Second, DisableAfter: 6})
+	errFailed := errors.New("failed")
+	now := time.Unix(1000, 0)
+
+	steps := []struct {
+		err     error
+		state   CollectorState
+		backoff time.Duration
+	}{
+		{errFailed, StateDegraded, 0},
+		{errFailed, StateBackingOff, time.Second},
+		{errFailed, StateBackingOff, 2 * time.Second},
+		{nil, StateHealthy, 0}, // 成功一次即恢复，退避时间重置
+		{errFailed, StateDegraded, 0},
+		{errFailed, StateBackingOff, time.Second},
+		{errFailed, StateBackingOff, 2 * time.Second},
+		{errFailed, StateBackingOff, 3 * time.Second}, // 不超过 max
+		{errFailed, StateBackingOff, 3 * time.Second},
+		{errFailed, StateDisabled, 3 * time.Second},
+	}
+	for i, s := range steps {
+		_, to := h.record(s.err, now)
+		if to != s.state || h.currentBackoff() != s.backoff {
+			t.Fatalf("step %d: state=%s backoff=%v, want %s %v", i, to, h.currentBackoff(), s.state, s.backoff)
+		}
+		if to == StateBackingOff {
+			if h.ready(now.Add(s.backoff - time.Millisecond)) {
+				t.Errorf("step %d: ready before backoff expired", i)
+			}
+			if !h.ready(now.Add(s.backoff)) {
+				t.Errorf("step %d: not ready after backoff expired", i)
+			}
+		}
+	}
+	if h.ready(now.Add(time.Hour)) {
+		t.Error("disabled collector must not be ready")
+	}
+}
+
+func TestCollectorHealthInit(t *testing.T) {
+	h := newCollectorHealth(config.BackoffConfig{Threshold: 2, Initial: time.Second, Max: 3 * time.Second})
+	errInit := errors.New("no such file")
+	now := time.Unix(1000, 0)
+
+	if _, to := h.initFailed(errInit); to != StateInitFailed || h.ready(now) {
+		t.Fatalf("after init failure: state=%s ready=%v, want init_failed and not ready", to, h.ready(now))
+	}
+	// 停用后重新启用：Init 仍未成功，回到 init_failed 而不是 healthy
+	h.setEnabled(false)
+	if _, to := h.setEnabled(true); to != StateInitFailed {
+		t.Fatalf("re-enabled before init succeeded: state=%s, want init_failed", to)
+	}
+	// 停用期间 Init 成功：保持停用，重新启用后回到 healthy
+	h.setEnabled(false)
+	if _, to := h.initialized(); to != StateDisabled {
+		t.Fatalf("initialized while disabled: state=%s, want disabled", to)
+	}
+	if _, to := h.setEnabled(true); to != StateHealthy || !h.ready(now) {
+		t.Fatalf("re-enabled after init succeeded: state=%s, want healthy", to)
+	}
+	if _, _, err := h.snapshot(); err != nil {
+		t.Errorf("last error = %v, want cleared after init succeeded", err)
+	}
+}
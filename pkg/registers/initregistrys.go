@@ -2,23 +2,95 @@ package registers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/agent-collector/pkg/config"
 	"github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	"hash/fnv"
+	"os"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// DefaultWorkers 未配置 metrics.workers 时同时运行的采集器数量上限
+const DefaultWorkers = 4
+
+// DefaultInitRetry 未配置 metrics.init_retry.initial 时 Init 失败后首次重试前的等待时间
+const DefaultInitRetry = 10 * time.Second
+
 // AgentImpl 实现 registers.Agent 接口
+// 每个采集器有独立的采集间隔和超时（见 Scheduled 和 metrics.schedules），调度器记录各自的下一次到期时间，
+// 到期时用带 deadline 的 context 调用 Collect；同一时刻到期的采集器共享一份 procfs 快照
+// 到期的采集器并发执行，同时运行的数量不超过 workers；同一个采集器上一次还没结束时跳过本次，不会重叠执行
+// Collect 中的 panic 会被恢复并计为失败，连续失败的采集器按 metrics.backoff 退避（见 collectorHealth）
+// Init 失败的采集器不参与调度，在后台按 metrics.init_retry 重试，成功后自动开始采集
+// metrics.align 开启时到期时间对齐到采集间隔的整数倍；metrics.jitter 为每个采集器加上由 machine-id 计算的固定偏移（见 firstDue）
 type AgentImpl struct {
-	collectors []Collector
+	collectors []*scheduledCollector
+	fs         procfs.FS // 每轮快照读取文件使用的文件系统
 	interval   time.Duration
-	ticker     *time.Ticker
+	align      bool           // 到期时间对齐到采集间隔的整数倍
+	jitter     time.Duration  // 固定偏移上限，0 为不偏移
+	hostID     string         // 计算偏移使用的主机标识（machine-id）
+	mode       string         // ModePush / ModeScrape
+	workers    chan struct{}  // 并发令牌，容量即 worker 上限
+	inflight   sync.WaitGroup // 调度启动、尚未结束的采集，关闭前等待
+	schedules  map[string]config.ScheduleConfig
+	overruns   *prometheus.CounterVec // 采集耗时超过超时时间的次数
+	skipped    *prometheus.CounterVec // 因上一次采集过慢错过的周期数
+	backoff    config.BackoffConfig
+	initRetry  config.InitRetryConfig
+	retries    sync.WaitGroup // 后台 Init 重试协程，关闭前等待
+	health     healthMetrics
 	ctx        context.Context
 	cancel     context.CancelFunc
 	mu         sync.Mutex
 }
 
+// scheduledCollector 单个采集器及其调度状态
+type scheduledCollector struct {
+	Collector
+	interval  time.Duration
+	timeout   time.Duration
+	offset    time.Duration // 由主机标识和采集器名称计算的固定偏移，[0, min(jitter, interval))
+	next      time.Time     // 下一次到期时间（只在调度协程中读写）
+	running   atomic.Bool   // 正在采集，防止同一采集器重叠执行
+	health    *collectorHealth
+	required  bool          // 必需采集器：Init 失败时启动失败
+	configKey string        // 配置键（工厂名称，如 proc），metrics.schedules 优先按它查找
+	initDone  chan struct{} // Init 成功后关闭
+}
+
+// RegisterOption 注册采集器时的可选参数
+type RegisterOption func(*scheduledCollector)
+
+// Required 注册为必需采集器：Init 失败时 Start 最多等待 metrics.init_retry.required_wait，仍失败则返回错误
+// 未标记的采集器为可选采集器，Init 失败时跳过并在后台重试
+func Required() RegisterOption {
+	return func(sc *scheduledCollector) { sc.required = true }
+}
+
+// ConfigKey 设置采集器的配置键（工厂名称）：metrics.schedules 先按配置键查找，找不到再按 Name() 查找，
+// 这样 schedules 与 collectors.<name> 可以使用同一个名称
+func ConfigKey(key string) RegisterOption {
+	return func(sc *scheduledCollector) { sc.configKey = key }
+}
+
+// healthMetrics 采集器健康状态相关指标
+type healthMetrics struct {
+	state       *prometheus.GaugeVec
+	transitions *prometheus.CounterVec
+	failures    *prometheus.GaugeVec
+	panics      *prometheus.CounterVec
+	initFailed  *prometheus.GaugeVec
+}
+
 //// GetRegisteredCollectors 返回所有已注册的采集器（返回副本，避免外部修改）
 //func (r *Registry) GetRegisteredCollectors() []Collector {
 //	copied := make([]Collector, len(r.collectors))
@@ -26,103 +98,533 @@ type AgentImpl struct {
 //	return copied
 //}
 
-// NewRegistry 创建采集器注册器（初始化上下文）
-func NewRegistry(interval time.Duration) *AgentImpl {
+// NewRegistry 创建采集器注册器（初始化上下文），fsys 为每轮快照读取 /proc、/sys 使用的文件系统
+func NewRegistry(monitor *config.MonitorConfig, metricFactory metrics.MetricFactory, fsys procfs.FS) *AgentImpl {
 	ctx, cancel := context.WithCancel(context.Background())
+	workers := monitor.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	initRetry := monitor.InitRetry
+	if initRetry.Initial <= 0 {
+		initRetry.Initial = DefaultInitRetry
+	}
+	if initRetry.Max < initRetry.Initial {
+		initRetry.Max = initRetry.Initial
+	}
+	var hostID string
+	if monitor.Jitter > 0 {
+		hostID = hostIdentity(fsys)
+	}
 	return &AgentImpl{
-		collectors: make([]Collector, 0),
-		interval:   interval,
-		ctx:        ctx,
-		cancel:     cancel,
+		collectors: make([]*scheduledCollector, 0),
+		fs:         fsys,
+		interval:   monitor.Interval,
+		align:      monitor.Align,
+		jitter:     monitor.Jitter,
+		hostID:     hostID,
+		mode:       monitor.Mode,
+		workers:    make(chan struct{}, workers),
+		schedules:  monitor.Schedules,
+		overruns:   metricFactory.NewAgentCollectorOverrunsTotal(),
+		skipped:    metricFactory.NewAgentCollectorSkippedCyclesTotal(),
+		backoff:    monitor.Backoff,
+		initRetry:  initRetry,
+		health: healthMetrics{
+			state:       metricFactory.NewAgentCollectorState(),
+			transitions: metricFactory.NewAgentCollectorStateTransitionsTotal(),
+			failures:    metricFactory.NewAgentCollectorConsecutiveFailures(),
+			panics:      metricFactory.NewAgentCollectorPanicsTotal(),
+			initFailed:  metricFactory.NewAgentCollectorInitFailed(),
+		},
+		ctx:    ctx,
+		cancel: cancel,
 	}
 }
 
-// Register 注册采集器
-func (r *AgentImpl) Register(c Collector) {
+// Register 注册采集器，同时确定它的采集间隔和超时
+func (r *AgentImpl) Register(c Collector, opts ...RegisterOption) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.collectors = append(r.collectors, c)
+	sc := &scheduledCollector{
+		Collector: c,
+		health:    newCollectorHealth(r.backoff),
+		initDone:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(sc)
+	}
+	interval, timeout := r.schedule(c, sc.configKey)
+	sc.interval, sc.timeout = interval, timeout
+	sc.offset = jitterOffset(r.hostID, c.Name(), min(r.jitter, interval))
+	r.collectors = append(r.collectors, sc)
+	r.setState(c.Name(), StateHealthy)
+	r.health.failures.WithLabelValues(c.Name()).Set(0)
+	logger.Debug("collector scheduled", zap.String("name", c.Name()),
+		zap.Duration("interval", interval), zap.Duration("timeout", timeout), zap.Duration("offset", sc.offset))
+}
+
+// hostIdentity 计算偏移使用的主机标识：machine-id，读取失败时退回主机名
+func hostIdentity(fsys procfs.FS) string {
+	id, err := procfs.MachineID(fsys)
+	if err == nil {
+		return id
+	}
+	hostname, _ := os.Hostname()
+	logger.Warn("machine-id unavailable, using hostname for schedule jitter", zap.String("hostname", hostname), zap.Error(err))
+	return hostname
+}
+
+// jitterOffset 由主机标识和采集器名称哈希得到 [0, limit) 内的固定偏移：同一主机每次启动结果相同，不同主机、不同采集器彼此错开
+func jitterOffset(hostID, name string, limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(hostID))
+	h.Write([]byte{0})
+	h.Write([]byte(name))
+	return time.Duration(h.Sum64() % uint64(limit))
+}
+
+// firstDue 第一次到期时间，之后每次加一个采集间隔（见 advance），偏移和对齐在整个运行期间保持不变
+// 不对齐时为 now + offset（未配置 jitter 时即立即采集）；对齐时为不早于 now 的「采集间隔整数倍 + offset」，
+// 整数倍按 UTC 零点起算，能整除一天的间隔（如 10s、1m、5m）在所有主机上落在相同的墙上时间
+func (r *AgentImpl) firstDue(sc *scheduledCollector, now time.Time) time.Time {
+	if !r.align {
+		return now.Add(sc.offset)
+	}
+	due := now.Truncate(sc.interval).Add(sc.offset)
+	if due.Before(now) {
+		due = due.Add(sc.interval)
+	}
+	return due
 }
 
-// InitAll 辅助方法（修复逻辑+优化）
-func (r *AgentImpl) InitAll() error {
-	for _, coll := range r.collectors { // 重命名循环变量，避免覆盖
-		if err := coll.Init(); err != nil {
-			return fmt.Errorf("collector init failed: %w", err)
+// schedule 按「配置文件 > 采集器声明 > 全局 interval」的优先级确定调度参数，超时默认等于采集间隔
+// 采集器声明的超时比采集间隔长时把间隔提高到超时并告警，不能悄悄截短超时，否则慢目标总是在自身超时之前被取消
+// 配置文件中先按配置键 key（工厂名称）查找，再按采集器名称查找
+func (r *AgentImpl) schedule(c Collector, key string) (interval, timeout time.Duration) {
+	if s, ok := c.(Scheduled); ok {
+		interval, timeout = s.Schedule()
+	}
+	s, ok := r.schedules[key]
+	if !ok || key == "" {
+		s, ok = r.schedules[c.Name()]
+	}
+	if ok {
+		if s.Interval > 0 {
+			interval = s.Interval
+		}
+		if s.Timeout > 0 {
+			timeout = s.Timeout
 		}
-		logger.Debug("collector initialized successfully", zap.String("name", coll.Name()))
 	}
-	return nil
+	if interval <= 0 {
+		interval = r.interval
+	}
+	if timeout <= 0 {
+		timeout = interval
+	}
+	if timeout > interval {
+		logger.Warn("collector timeout exceeds its interval, interval raised to the timeout",
+			zap.String("name", c.Name()), zap.Duration("interval", interval), zap.Duration("timeout", timeout))
+		interval = timeout
+	}
+	return interval, timeout
+}
+
+// InitAll 依次初始化所有采集器，Init 失败（包括 panic）的采集器进入 init_failed 并在后台按 metrics.init_retry 重试
+// 可选采集器失败只告警；必需采集器失败时最多等待 init_retry.required_wait，
+// 仍未成功则停止所有重试、关闭所有采集器并返回错误
+func (r *AgentImpl) InitAll(ctx context.Context) error {
+	var required []*scheduledCollector
+	for _, sc := range r.collectors {
+		err := r.initOne(sc)
+		if err == nil {
+			r.markInitialized(sc)
+			logger.Debug("collector initialized successfully", zap.String("name", sc.Name()))
+			continue
+		}
+		r.markInitFailed(sc, err)
+		if sc.required {
+			logger.Error("required collector init failed, retrying", zap.String("name", sc.Name()),
+				zap.Duration("required_wait", r.initRetry.RequiredWait), zap.Error(err))
+			required = append(required, sc)
+		} else {
+			logger.Warn("collector init failed, skipped and retrying in background", zap.String("name", sc.Name()),
+				zap.Duration("retry", r.initRetry.Initial), zap.Error(err))
+		}
+		r.retries.Add(1)
+		go r.retryInit(ctx, sc)
+	}
+	return r.waitRequired(ctx, required)
+}
+
+// waitRequired 等待必需采集器的后台重试成功，超过 init_retry.required_wait 后返回仍未成功的采集器的错误
+func (r *AgentImpl) waitRequired(ctx context.Context, required []*scheduledCollector) error {
+	if len(required) == 0 {
+		return nil
+	}
+	wctx, cancel := context.WithTimeout(ctx, r.initRetry.RequiredWait)
+	defer cancel()
+wait:
+	for _, sc := range required {
+		select {
+		case <-sc.initDone:
+		case <-wctx.Done():
+			break wait
+		}
+	}
+
+	var errs []error
+	for _, sc := range required {
+		select {
+		case <-sc.initDone:
+			continue
+		default:
+		}
+		if _, _, err := sc.health.snapshot(); err != nil {
+			errs = append(errs, fmt.Errorf("required collector %s init failed: %w", sc.Name(), err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	r.cancel()
+	r.retries.Wait()
+	_ = r.CloseAll()
+	return errors.Join(errs...)
+}
+
+// retryInit 按指数退避在后台重试 Init，直到成功或 agent 停止
+func (r *AgentImpl) retryInit(ctx context.Context, sc *scheduledCollector) {
+	defer r.retries.Done()
+	delay := r.initRetry.Initial
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		case <-r.ctx.Done():
+			return
+		}
+		if err := r.initOne(sc); err != nil {
+			r.markInitFailed(sc, err)
+			delay = min(delay*2, r.initRetry.Max)
+			logger.Debug("collector init retry failed", zap.String("name", sc.Name()), zap.Int("attempt", attempt),
+				zap.Duration("next_retry", delay), zap.Error(err))
+			continue
+		}
+		r.markInitialized(sc)
+		logger.Info("collector initialized after retry", zap.String("name", sc.Name()), zap.Int("attempt", attempt))
+		return
+	}
 }
 
-// Start 启动采集器（移除冗余参数，关联外部上下文）
-func (r *AgentImpl) Start(ctx context.Context) {
-	// 初始化所有已注册采集器（初始化失败直接终止）
-	if err := r.InitAll(); err != nil {
-		logger.Fatal("failed to init all collectors", zap.String("name", "collector-registry"), zap.Error(err))
+// initOne 调用 Init 并恢复其中的 panic，panic 转换为错误
+func (r *AgentImpl) initOne(sc *scheduledCollector) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			logger.Error("collector init panicked", zap.String("name", sc.Name()), zap.Any("panic", p), zap.ByteString("stack", debug.Stack()))
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return sc.Init()
+}
+
+// markInitFailed 记录 Init 失败：进入 init_failed（停用中的保持 disabled），agent_collector_init_failed 置 1
+func (r *AgentImpl) markInitFailed(sc *scheduledCollector, err error) {
+	r.health.initFailed.WithLabelValues(sc.Name()).Set(1)
+	if from, to := sc.health.initFailed(err); from != to {
+		r.transition(sc.Name(), from, to)
 	}
+}
+
+// markInitialized 记录 Init 成功：从 init_failed 回到 healthy，agent_collector_init_failed 置 0
+func (r *AgentImpl) markInitialized(sc *scheduledCollector) {
+	r.health.initFailed.WithLabelValues(sc.Name()).Set(0)
+	if from, to := sc.health.initialized(); from != to {
+		r.transition(sc.Name(), from, to)
+	}
+	close(sc.initDone)
+}
 
-	// 启动定时器
-	r.ticker = time.NewTicker(r.interval)
+// Start 初始化所有采集器（见 InitAll）并启动采集，必需采集器初始化失败时返回错误
+func (r *AgentImpl) Start(ctx context.Context) error {
+	if err := r.InitAll(ctx); err != nil {
+		return fmt.Errorf("init collectors: %w", err)
+	}
+
+	if r.mode == ModeScrape {
+		// scrape 模式不启动调度协程，由 ScrapeCollector 在收到 scrape 请求时触发采集
+		logger.Debug("collector metrics started in scrape mode", zap.String("name", "collector-registry"),
+			zap.Int("registered-collectors-count", len(r.collectors)))
+		return nil
+	}
+
+	// 未配置对齐和偏移时所有采集器启动后立即采集一次，之后按各自的间隔调度
+	now := time.Now()
+	for _, sc := range r.collectors {
+		sc.next = r.firstDue(sc, now)
+	}
 	logger.Debug("collector metrics started", zap.String("name", "collector-registry"),
 		zap.Duration("interval", r.interval),
+		zap.Bool("align", r.align),
+		zap.Duration("jitter", r.jitter),
+		zap.Int("workers", cap(r.workers)),
 		zap.Int("registered-collectors-count", len(r.collectors)))
 
 	// 异步采集：同时监听外部 ctx 和内部 ctx 的关闭信号
 	go func() {
-		// 首次采集（失败仅警告）
-		if err := r.CollectAll(ctx); err != nil {
-			logger.Warn("first collection failed", zap.String("name", "collector-registry"), zap.Error(err))
-		}
+		timer := time.NewTimer(0)
+		defer timer.Stop()
 
 		for {
 			select {
-			case <-r.ticker.C:
-				_ = r.CollectAll(ctx) // 单采集器失败不影响整体
+			case <-timer.C:
+				r.collectDue(ctx, time.Now()) // 单采集器失败不影响整体
+				timer.Reset(time.Until(r.nextDue()))
 			case <-ctx.Done(): // 响应外部关闭信号（如服务停止）
-				r.ticker.Stop()
 				logger.Info("collector metrics stopped by external context", zap.String("name", "collector-registry"), zap.Error(ctx.Err()))
 				return
 			case <-r.ctx.Done(): // 响应内部关闭信号（如主动调用 Shutdown）
-				r.ticker.Stop()
 				logger.Info("collector metrics stopped by internal shutdown", zap.String("name", "collector-registry"))
 				return
 			}
 		}
 	}()
+	return nil
 }
 
 // Shutdown 优雅关闭采集器（释放资源）
 func (r *AgentImpl) Shutdown(ctx context.Context) error {
 	logger.Info("starting to shutdown collector metrics", zap.String("name", "collector-registry"))
 
-	// 停止定时器（双重保险）
-	if r.ticker != nil {
-		r.ticker.Stop()
-	}
-
-	// 触发内部上下文取消，终止采集循环
+	// 触发内部上下文取消，终止采集循环；持锁取消，保证之后抓取或手动触发的采集不会再登记到 inflight
+	r.mu.Lock()
 	r.cancel()
+	r.mu.Unlock()
+
+	// 等待进行中的采集和 Init 重试结束再关闭采集器，避免 Close 与 Collect/Init 并发
+	done := make(chan struct{})
+	go func() {
+		r.inflight.Wait()
+		r.retries.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Warn("timed out waiting for in-flight collections and init retries", zap.String("name", "collector-registry"), zap.Error(ctx.Err()))
+	}
 
 	// 关闭所有采集器，返回最后一个错误
 	return r.CloseAll()
 }
 
-// CollectAll 批量采集数据（优化日志输出）
+// CollectAll 立即并发采集所有采集器（不影响调度），返回各采集器错误合并后的 multi-error
+// 每轮创建一份 procfs/sysfs 快照放入 ctx，同一文件在本轮内只读取一次，所有采集器看到一致的内容
 func (r *AgentImpl) CollectAll(ctx context.Context) error {
-	var hasErr bool
-	for _, collector := range r.collectors {
-		if err := collector.Collect(ctx); err != nil {
-			logger.Warn("collection failed", zap.String("name", collector.Name()), zap.Error(err))
-			hasErr = true
+	return r.collectNow(ctx, false)
+}
+
+// collectNow 并发采集所有采集器并等待结束；honorBackoff 为 true 时跳过退避中的采集器（scrape 模式）
+func (r *AgentImpl) collectNow(ctx context.Context, honorBackoff bool) error {
+	snapshot := procfs.NewSnapshot(r.fs)
+	defer snapshot.Release()
+	ctx = procfs.WithSnapshot(ctx, snapshot)
+
+	now := time.Now()
+	errs := make([]error, len(r.collectors))
+	var wg sync.WaitGroup
+	for i, sc := range r.collectors {
+		if honorBackoff && !sc.health.ready(now) {
+			continue
+		}
+		if err := sc.unavailable(); err != nil {
+			errs[i] = err
+			continue
+		}
+		if !sc.running.CompareAndSwap(false, true) {
+			errs[i] = fmt.Errorf("%s: %w", sc.Name(), ErrCollectorBusy)
+			continue
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.collectOne(ctx, sc); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", sc.Name(), err)
+			}
+		}()
 	}
-	if hasErr {
-		return fmt.Errorf("some collectors failed to collect data")
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+var (
+	ErrCollectorBusy           = errors.New("previous collection still running") // 采集器上一次采集尚未结束
+	ErrCollectorDisabled       = errors.New("collector is disabled")             // 连续失败次数过多或被手动停用
+	ErrCollectorNotFound       = errors.New("collector not found")               // 管理接口指定的采集器不存在
+	ErrCollectorNotInitialized = errors.New("collector is not initialized")      // Init 失败，正在后台重试
+	ErrAgentStopped            = errors.New("agent is shutting down")            // Shutdown 之后不再接受手动触发
+)
+
+// unavailable 采集器已停用或尚未初始化成功时返回对应错误
+func (sc *scheduledCollector) unavailable() error {
+	switch state, _, _ := sc.health.snapshot(); state {
+	case StateDisabled:
+		return fmt.Errorf("%s: %w", sc.Name(), ErrCollectorDisabled)
+	case StateInitFailed:
+		return fmt.Errorf("%s: %w", sc.Name(), ErrCollectorNotInitialized)
 	}
 	return nil
 }
 
+// collectDue 启动所有到期的采集器并推进它们的下一次到期时间，不等待采集结束
+// 本轮启动的采集全部结束后才释放快照
+func (r *AgentImpl) collectDue(ctx context.Context, now time.Time) {
+	snapshot := procfs.NewSnapshot(r.fs)
+	ctx = procfs.WithSnapshot(ctx, snapshot)
+
+	var wg sync.WaitGroup
+	for _, sc := range r.collectors {
+		if sc.next.After(now) {
+			continue
+		}
+		r.advance(sc, now)
+		if !sc.health.ready(now) {
+			// 退避中或已停用：不算作跳过的周期
+			continue
+		}
+		if !sc.running.CompareAndSwap(false, true) {
+			// 上一次采集还没结束：本周期跳过，不排队
+			r.skipped.WithLabelValues(sc.Name()).Inc()
+			logger.Warn("collector still running, skip this cycle", zap.String("name", sc.Name()), zap.Duration("interval", sc.interval))
+			continue
+		}
+		wg.Add(1)
+		r.inflight.Add(1)
+		go func() {
+			defer r.inflight.Done()
+			defer wg.Done()
+			_ = r.collectOne(ctx, sc)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		snapshot.Release()
+	}()
+}
+
+// collectOne 占用一个 worker 令牌，以带超时的 context 执行一次采集，耗时超过超时时间时记一次 overrun
+// 调用方需先将 sc.running 置为 true，结束后在这里复位；Collect 需要自行响应 ctx.Done()，调度器不会强行中断
+func (r *AgentImpl) collectOne(ctx context.Context, sc *scheduledCollector) error {
+	defer sc.running.Store(false)
+	select {
+	case r.workers <- struct{}{}:
+		defer func() { <-r.workers }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, sc.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := r.safeCollect(cctx, sc)
+	elapsed := time.Since(start)
+	sc.health.observe(start, elapsed)
+	if elapsed > sc.timeout {
+		r.overruns.WithLabelValues(sc.Name()).Inc()
+		logger.Warn("collection exceeded timeout", zap.String("name", sc.Name()),
+			zap.Duration("elapsed", elapsed), zap.Duration("timeout", sc.timeout))
+	}
+	if err != nil {
+		logger.Warn("collection failed", zap.String("name", sc.Name()), zap.Error(err))
+	}
+	r.recordResult(sc, err)
+	return err
+}
+
+// safeCollect 调用 Collect 并恢复其中的 panic，panic 转换为错误，不影响调度协程和其他采集器
+func (r *AgentImpl) safeCollect(ctx context.Context, sc *scheduledCollector) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			r.health.panics.WithLabelValues(sc.Name()).Inc()
+			logger.Error("collector panicked", zap.String("name", sc.Name()), zap.Any("panic", p), zap.ByteString("stack", debug.Stack()))
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return sc.Collect(ctx)
+}
+
+// recordResult 更新采集器状态机，状态变化时记录日志和指标
+func (r *AgentImpl) recordResult(sc *scheduledCollector, err error) {
+	from, to := sc.health.record(err, time.Now())
+	_, failures, _ := sc.health.snapshot()
+	r.health.failures.WithLabelValues(sc.Name()).Set(float64(failures))
+	if from == to {
+		return
+	}
+	r.transition(sc.Name(), from, to)
+	fields := []zap.Field{zap.String("name", sc.Name()), zap.String("from", string(from)), zap.String("to", string(to)), zap.Int("consecutive_failures", failures)}
+	switch to {
+	case StateHealthy:
+		logger.Info("collector recovered", fields...)
+	case StateBackingOff:
+		logger.Warn("collector backing off", append(fields, zap.Duration("backoff", sc.health.currentBackoff()), zap.Error(err))...)
+	case StateDisabled:
+		logger.Error("collector disabled after consecutive failures", append(fields, zap.Error(err))...)
+	default:
+		logger.Warn("collector degraded", append(fields, zap.Error(err))...)
+	}
+}
+
+// transition 导出一次状态切换
+func (r *AgentImpl) transition(name string, from, to CollectorState) {
+	r.setState(name, to)
+	r.health.transitions.WithLabelValues(name, string(from), string(to)).Inc()
+}
+
+// setState 将 agent_collector_state 中当前状态置 1，其余置 0
+func (r *AgentImpl) setState(name string, state CollectorState) {
+	for _, s := range collectorStates {
+		v := 0.0
+		if s == state {
+			v = 1
+		}
+		r.health.state.WithLabelValues(name, string(s)).Set(v)
+	}
+}
+
+// advance 推进下一次到期时间；调度协程被耽误导致到期时间已经过去时跳过错过的周期，不补采
+func (r *AgentImpl) advance(sc *scheduledCollector, now time.Time) {
+	sc.next = sc.next.Add(sc.interval)
+	if sc.next.After(now) {
+		return
+	}
+	missed := now.Sub(sc.next)/sc.interval + 1
+	r.skipped.WithLabelValues(sc.Name()).Add(float64(missed))
+	sc.next = sc.next.Add(missed * sc.interval)
+	logger.Warn("collector skipped cycles", zap.String("name", sc.Name()),
+		zap.Int64("skipped", int64(missed)), zap.Duration("interval", sc.interval))
+}
+
+// nextDue 最早的下一次到期时间
+func (r *AgentImpl) nextDue() time.Time {
+	var next time.Time
+	for _, sc := range r.collectors {
+		if next.IsZero() || sc.next.Before(next) {
+			next = sc.next
+		}
+	}
+	if next.IsZero() {
+		return time.Now().Add(r.interval)
+	}
+	return next
+}
+
 // CloseAll 批量关闭采集器（优化错误收集）
 func (r *AgentImpl) CloseAll() error {
 	var lastErr error
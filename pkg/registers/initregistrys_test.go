@@ -0,0 +1,463 @@
+package registers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/config"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeCollector 每次采集耗时 delay，记录调用次数、最大并发数和收到的 context deadline
+type fakeCollector struct {
+	name     string
+	delay    time.Duration
+	err      error
+	interval time.Duration // 通过 Schedule 声明的默认值
+	timeout  time.Duration
+	calls    atomic.Int32
+	deadline atomic.Int64
+	active   *atomic.Int32 // 多个采集器共享，统计同时运行的数量
+	peak     *atomic.Int32
+}
+
+func (f *fakeCollector) Name() string { return f.name }
+func (f *fakeCollector) Init() error  { return nil }
+func (f *fakeCollector) Close() error { return nil }
+func (f *fakeCollector) Collect(ctx context.Context) error {
+	f.calls.Add(1)
+	if d, ok := ctx.Deadline(); ok {
+		f.deadline.Store(int64(time.Until(d)))
+	}
+	if f.active != nil {
+		n := f.active.Add(1)
+		defer f.active.Add(-1)
+		for {
+			p := f.peak.Load()
+			if n <= p || f.peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+	}
+	time.Sleep(f.delay)
+	return f.err
+}
+
+type scheduledFake struct{ *fakeCollector }
+
+func (f scheduledFake) Schedule() (time.Duration, time.Duration) { return f.interval, f.timeout }
+
+func newTestAgent(workers int, schedules map[string]config.ScheduleConfig) *AgentImpl {
+	factory := *metrics.NewMetricFactory(metrics.NewPromRegistry(prometheus.NewRegistry()))
+	return NewRegistry(&config.MonitorConfig{
+		Interval:  10 * time.Second,
+		Workers:   workers,
+		Schedules: schedules,
+		Backoff:   config.BackoffConfig{Threshold: 3, Initial: 10 * time.Second, Max: time.Minute},
+	}, factory, procfs.OSFS{})
+}
+
+func TestScheduleResolution(t *testing.T) {
+	r := newTestAgent(0, map[string]config.ScheduleConfig{
+		"configured": {Interval: time.Minute},
+		"timeout":    {Timeout: 3 * time.Second},
+	})
+	r.Register(&fakeCollector{name: "default"})
+	r.Register(scheduledFake{&fakeCollector{name: "declared", interval: 30 * time.Second, timeout: 5 * time.Second}})
+	r.Register(scheduledFake{&fakeCollector{name: "configured", interval: 30 * time.Second, timeout: 5 * time.Second}})
+	r.Register(&fakeCollector{name: "timeout"})
+
+	want := map[string][2]time.Duration{
+		"default":    {10 * time.Second, 10 * time.Second},
+		"declared":   {30 * time.Second, 5 * time.Second},
+		"configured": {time.Minute, 5 * time.Second},
+		"timeout":    {10 * time.Second, 3 * time.Second},
+	}
+	for _, sc := range r.collectors {
+		if got := [2]time.Duration{sc.interval, sc.timeout}; got != want[sc.Name()] {
+			t.Errorf("%s: interval/timeout = %v, want %v", sc.Name(), got, want[sc.Name()])
+		}
+	}
+}
+
+// TestScheduleTimeoutExceedsInterval 采集器声明的超时长于采集间隔（如探测目标超时 5s、全局 interval 2s）时，
+// 间隔提高到超时，而不是把超时截短到间隔
+func TestScheduleTimeoutExceedsInterval(t *testing.T) {
+	r := newTestAgent(0, map[string]config.ScheduleConfig{
+		"configured-short": {Interval: 2 * time.Second},
+		"configured-both":  {Interval: 2 * time.Second, Timeout: time.Second},
+	})
+	r.Register(scheduledFake{&fakeCollector{name: "global", timeout: 30 * time.Second}})
+	r.Register(scheduledFake{&fakeCollector{name: "declared", interval: 5 * time.Second, timeout: 6 * time.Second}})
+	r.Register(scheduledFake{&fakeCollector{name: "configured-short", timeout: 6 * time.Second}})
+	r.Register(scheduledFake{&fakeCollector{name: "configured-both", timeout: 6 * time.Second}})
+
+	want := map[string][2]time.Duration{
+		"global":           {30 * time.Second, 30 * time.Second},
+		"declared":         {6 * time.Second, 6 * time.Second},
+		"configured-short": {6 * time.Second, 6 * time.Second},
+		"configured-both":  {2 * time.Second, time.Second}, // 配置文件显式缩短超时时不需要调整间隔
+	}
+	for _, sc := range r.collectors {
+		if got := [2]time.Duration{sc.interval, sc.timeout}; got != want[sc.Name()] {
+			t.Errorf("%s: interval/timeout = %v, want %v", sc.Name(), got, want[sc.Name()])
+		}
+	}
+}
+
+func TestCollectAllOverrunAndErrors(t *testing.T) {
+	r := newTestAgent(2, map[string]config.ScheduleConfig{
+		"slow": {Timeout: 10 * time.Millisecond},
+	})
+	errBroken := errors.New("broken")
+	slow := &fakeCollector{name: "slow", delay: 30 * time.Millisecond}
+	r.Register(slow)
+	r.Register(&fakeCollector{name: "broken-a", err: errBroken})
+	r.Register(&fakeCollector{name: "broken-b", err: errBroken})
+
+	err := r.CollectAll(context.Background())
+	if !errors.Is(err, errBroken) {
+		t.Fatalf("CollectAll error = %v, want it to wrap %v", err, errBroken)
+	}
+	for _, name := range []string{"broken-a", "broken-b"} {
+		if !strings.Contains(err.Error(), name+": broken") {
+			t.Errorf("error %q does not mention %s", err, name)
+		}
+	}
+	if d := time.Duration(slow.deadline.Load()); d <= 0 || d > 10*time.Millisecond {
+		t.Errorf("collect deadline = %v, want within the 10ms timeout", d)
+	}
+	if got := testutil.ToFloat64(r.overruns.WithLabelValues("slow")); got != 1 {
+		t.Errorf("overruns = %v, want 1", got)
+	}
+}
+
+func TestCollectDueBoundedAndNoOverlap(t *testing.T) {
+	r := newTestAgent(2, nil)
+	var active, peak atomic.Int32
+	var fakes []*fakeCollector
+	for _, name := range []string{"a", "b", "c", "d"} {
+		f := &fakeCollector{name: name, delay: 50 * time.Millisecond, active: &active, peak: &peak}
+		fakes = append(fakes, f)
+		r.Register(f)
+	}
+
+	start := time.Now()
+	for _, sc := range r.collectors {
+		sc.next = start
+	}
+	r.collectDue(context.Background(), start)
+	// 上一轮还没结束时再次到期：全部跳过，不会重叠执行
+	for _, sc := range r.collectors {
+		sc.next = start
+	}
+	r.collectDue(context.Background(), start)
+	r.inflight.Wait()
+
+	for _, f := range fakes {
+		if n := f.calls.Load(); n != 1 {
+			t.Errorf("%s: calls = %d, want 1", f.name, n)
+		}
+		if got := testutil.ToFloat64(r.skipped.WithLabelValues(f.name)); got != 1 {
+			t.Errorf("%s: skipped cycles = %v, want 1", f.name, got)
+		}
+	}
+	if p := peak.Load(); p != 2 {
+		t.Errorf("peak concurrency = %d, want 2", p)
+	}
+}
+
+func TestAdvanceSkipsMissedCycles(t *testing.T) {
+	r := newTestAgent(1, nil)
+	r.Register(&fakeCollector{name: "late"})
+	sc := r.collectors[0]
+
+	start := time.Now()
+	sc.next = start
+	r.advance(sc, start)
+	if got := sc.next.Sub(start); got != 10*time.Second {
+		t.Fatalf("next due = +%v, want +10s", got)
+	}
+	// 调度协程晚了 25s 才处理：错过 20s、30s 两个到期点，下一次在 40s
+	r.advance(sc, start.Add(35*time.Second))
+	if got := sc.next.Sub(start); got != 40*time.Second {
+		t.Errorf("next due = +%v, want +40s", got)
+	}
+	if got := testutil.ToFloat64(r.skipped.WithLabelValues("late")); got != 2 {
+		t.Errorf("skipped cycles = %v, want 2", got)
+	}
+	if !r.nextDue().Equal(sc.next) {
+		t.Errorf("nextDue = %v, want %v", r.nextDue(), sc.next)
+	}
+}
+
+func TestJitterOffset(t *testing.T) {
+	if got := jitterOffset("host-a", "cpu-collector", 0); got != 0 {
+		t.Errorf("offset without jitter = %v, want 0", got)
+	}
+	offsets := make(map[time.Duration]bool)
+	for i := 0; i < 20; i++ {
+		host := fmt.Sprintf("host-%d", i)
+		got := jitterOffset(host, "cpu-collector", 10*time.Second)
+		if got < 0 || got >= 10*time.Second {
+			t.Fatalf("offset for %s = %v, want within [0, 10s)", host, got)
+		}
+		if again := jitterOffset(host, "cpu-collector", 10*time.Second); again != got {
+			t.Fatalf("offset for %s not deterministic: %v then %v", host, got, again)
+		}
+		offsets[got] = true
+	}
+	if len(offsets) < 15 {
+		t.Errorf("20 hosts got only %d distinct offsets", len(offsets))
+	}
+	if jitterOffset("host-0", "cpu-collector", time.Minute) == jitterOffset("host-0", "probe-collector", time.Minute) {
+		t.Error("collectors on the same host share an offset")
+	}
+}
+
+func TestAlignedScheduleWithJitter(t *testing.T) {
+	factory := *metrics.NewMetricFactory(metrics.NewPromRegistry(prometheus.NewRegistry()))
+	fsys := procfs.MapFS{"/etc/machine-id": {Data: []byte("0123456789abcdef\n")}}
+	r := NewRegistry(&config.MonitorConfig{
+		Interval:  10 * time.Second,
+		Align:     true,
+		Jitter:    time.Minute, // 超过采集间隔时按采集间隔截断
+		Schedules: map[string]config.ScheduleConfig{"slow": {Interval: time.Minute}},
+		Backoff:   config.BackoffConfig{Threshold: 3, Initial: 10 * time.Second, Max: time.Minute},
+	}, factory, fsys)
+	r.Register(&fakeCollector{name: "fast"})
+	r.Register(&fakeCollector{name: "slow"})
+
+	if r.hostID != "0123456789abcdef" {
+		t.Fatalf("hostID = %q, want machine-id", r.hostID)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 3, int(500*time.Millisecond), time.UTC)
+	for _, sc := range r.collectors {
+		if want := jitterOffset("0123456789abcdef", sc.Name(), sc.interval); sc.offset != want {
+			t.Fatalf("%s offset = %v, want %v", sc.Name(), sc.offset, want)
+		}
+		// 第一次到期：不早于 now，且距离前一个整点（采集间隔整数倍）正好是 offset
+		due := r.firstDue(sc, now)
+		if due.Before(now) || due.Sub(now) >= sc.interval {
+			t.Errorf("%s first due %v, want within one interval after %v", sc.Name(), due, now)
+		}
+		if got := due.Sub(due.Add(-sc.offset).Truncate(sc.interval)); got != sc.offset {
+			t.Errorf("%s first due %v is %v past the boundary, want offset %v", sc.Name(), due, got, sc.offset)
+		}
+		// 之后每次到期保持相同的相位
+		sc.next = due
+		r.advance(sc, due)
+		if got := sc.next.Sub(sc.next.Truncate(sc.interval)); got != sc.offset {
+			t.Errorf("%s next due %v drifted: %v past the boundary, want %v", sc.Name(), sc.next, got, sc.offset)
+		}
+	}
+
+	// 不对齐、不偏移时立即采集
+	plain := newTestAgent(1, nil)
+	plain.Register(&fakeCollector{name: "plain"})
+	if due := plain.firstDue(plain.collectors[0], now); !due.Equal(now) {
+		t.Errorf("first due without align/jitter = %v, want now", due)
+	}
+}
+
+// panicCollector 每次采集都 panic
+type panicCollector struct{ fakeCollector }
+
+func (p *panicCollector) Collect(ctx context.Context) error { panic("boom") }
+
+func TestCollectRecoversPanic(t *testing.T) {
+	r := newTestAgent(1, nil)
+	r.Register(&panicCollector{fakeCollector{name: "panicky"}})
+	ok := &fakeCollector{name: "ok"}
+	r.Register(ok)
+
+	err := r.CollectAll(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "panicky: panic: boom") {
+		t.Fatalf("CollectAll error = %v, want the recovered panic", err)
+	}
+	if ok.calls.Load() != 1 {
+		t.Errorf("other collector calls = %d, want 1", ok.calls.Load())
+	}
+	if got := testutil.ToFloat64(r.health.panics.WithLabelValues("panicky")); got != 1 {
+		t.Errorf("panics = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(r.health.state.WithLabelValues("panicky", string(StateDegraded))); got != 1 {
+		t.Errorf("state{degraded} = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(r.health.state.WithLabelValues("panicky", string(StateHealthy))); got != 0 {
+		t.Errorf("state{healthy} = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(r.health.transitions.WithLabelValues("panicky", string(StateHealthy), string(StateDegraded))); got != 1 {
+		t.Errorf("transitions healthy->degraded = %v, want 1", got)
+	}
+}
+
+// initFlaky 在 ok 置为 true 之前 Init 一直失败
+type initFlaky struct {
+	fakeCollector
+	ok     atomic.Bool
+	inits  atomic.Int32
+	closed atomic.Bool
+}
+
+func (f *initFlaky) Init() error {
+	f.inits.Add(1)
+	if !f.ok.Load() {
+		return errors.New("socket not found")
+	}
+	return nil
+}
+
+func (f *initFlaky) Close() error {
+	f.closed.Store(true)
+	return nil
+}
+
+func newInitRetryAgent(requiredWait time.Duration) *AgentImpl {
+	factory := *metrics.NewMetricFactory(metrics.NewPromRegistry(prometheus.NewRegistry()))
+	return NewRegistry(&config.MonitorConfig{
+		Interval:  10 * time.Second,
+		Mode:      ModeScrape, // 不启动调度协程，测试中手动 CollectAll
+		Backoff:   config.BackoffConfig{Threshold: 3, Initial: 10 * time.Second, Max: time.Minute},
+		InitRetry: config.InitRetryConfig{Initial: 5 * time.Millisecond, Max: 20 * time.Millisecond, RequiredWait: requiredWait},
+	}, factory, procfs.OSFS{})
+}
+
+func TestInitFailureRetriedInBackground(t *testing.T) {
+	r := newInitRetryAgent(0)
+	optional := &initFlaky{fakeCollector: fakeCollector{name: "docker"}}
+	ok := &fakeCollector{name: "ok"}
+	r.Register(optional)
+	r.Register(ok)
+	if err := r.Start(t.Context()); err != nil {
+		t.Fatalf("Start with a failing optional collector: %v", err)
+	}
+	defer r.Shutdown(context.Background())
+
+	if got := testutil.ToFloat64(r.health.initFailed.WithLabelValues("docker")); got != 1 {
+		t.Errorf("init_failed{docker} = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(r.health.initFailed.WithLabelValues("ok")); got != 0 {
+		t.Errorf("init_failed{ok} = %v, want 0", got)
+	}
+	if status, _ := r.Collector("docker"); status.State != StateInitFailed || status.LastError == "" {
+		t.Errorf("status = %+v, want init_failed with last_error", status)
+	}
+	err := r.CollectAll(context.Background())
+	if !errors.Is(err, ErrCollectorNotInitialized) {
+		t.Fatalf("CollectAll error = %v, want ErrCollectorNotInitialized", err)
+	}
+	if optional.calls.Load() != 0 || ok.calls.Load() != 1 {
+		t.Errorf("calls docker=%d ok=%d, want 0 and 1", optional.calls.Load(), ok.calls.Load())
+	}
+
+	// 资源出现后后台重试成功，采集器自动开始采集
+	optional.ok.Store(true)
+	deadline := time.Now().Add(5 * time.Second)
+	for status, _ := r.Collector("docker"); status.State != StateHealthy; status, _ = r.Collector("docker") {
+		if time.Now().After(deadline) {
+			t.Fatalf("collector not initialized by background retry, status = %+v", status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if optional.inits.Load() < 2 {
+		t.Errorf("inits = %d, want at least one retry", optional.inits.Load())
+	}
+	if got := testutil.ToFloat64(r.health.initFailed.WithLabelValues("docker")); got != 0 {
+		t.Errorf("init_failed{docker} after retry = %v, want 0", got)
+	}
+	if err := r.CollectAll(context.Background()); err != nil {
+		t.Fatalf("CollectAll after retry: %v", err)
+	}
+	if optional.calls.Load() != 1 {
+		t.Errorf("docker calls after retry = %d, want 1", optional.calls.Load())
+	}
+}
+
+func TestRequiredCollectorInit(t *testing.T) {
+	t.Run("fails startup", func(t *testing.T) {
+		r := newInitRetryAgent(30 * time.Millisecond)
+		required := &initFlaky{fakeCollector: fakeCollector{name: "cgroup"}}
+		r.Register(required, Required())
+		err := r.Start(t.Context())
+		if err == nil || !strings.Contains(err.Error(), "required collector cgroup init failed: socket not found") {
+			t.Fatalf("Start error = %v, want required collector init failure", err)
+		}
+		if required.inits.Load() < 2 {
+			t.Errorf("inits = %d, want retries during required_wait", required.inits.Load())
+		}
+		if !required.closed.Load() {
+			t.Error("collectors not closed after startup failed")
+		}
+	})
+
+	t.Run("recovers within required_wait", func(t *testing.T) {
+		r := newInitRetryAgent(5 * time.Second)
+		required := &initFlaky{fakeCollector: fakeCollector{name: "cgroup"}}
+		r.Register(required, Required())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			required.ok.Store(true)
+		}()
+		if err := r.Start(t.Context()); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		defer r.Shutdown(context.Background())
+		if status, _ := r.Collector("cgroup"); status.State != StateHealthy || !status.Required {
+			t.Errorf("status = %+v, want healthy required collector", status)
+		}
+	})
+}
+
+func TestTriggerCollectorWaitedByShutdown(t *testing.T) {
+	r := newTestAgent(1, nil)
+	c := &closeTracker{fakeCollector: fakeCollector{name: "slow", delay: 200 * time.Millisecond}}
+	r.Register(c)
+	if err := r.Start(t.Context()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	// 等首次调度的采集结束，避免与手动触发冲突
+	deadline := time.Now().Add(5 * time.Second)
+	for status, _ := r.Collector("slow"); status.LastRun == nil || status.Running; status, _ = r.Collector("slow") {
+		if time.Now().After(deadline) {
+			t.Fatalf("first scheduled collection did not finish, status = %+v", status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	triggered := make(chan error, 1)
+	go func() { triggered <- r.TriggerCollector(context.Background(), "slow") }()
+	for c.calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("triggered collection did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	select {
+	case err := <-triggered:
+		if err != nil {
+			t.Errorf("triggered collection: %v", err)
+		}
+	default:
+		t.Error("Shutdown returned before the triggered collection finished")
+	}
+	if c.closedEarly.Load() {
+		t.Error("collector closed while the triggered collection was running")
+	}
+
+	if err := r.TriggerCollector(context.Background(), "slow"); !errors.Is(err, ErrAgentStopped) {
+		t.Errorf("trigger after Shutdown = %v, want ErrAgentStopped", err)
+	}
+}
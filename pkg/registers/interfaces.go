@@ -1,13 +1,23 @@
 package registers
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Agent 顶层采集器接口（封装所有采集器的生命周期管理）
 // 后续扩展采集器仅需实现Collector接口，通过Agent注册即可
 type Agent interface {
-	Register(collector Collector)       // 注册采集器
-	Start(ctx context.Context)          // 启动采集（定时器循环）
-	Shutdown(ctx context.Context) error // 优雅停止
+	Register(collector Collector, opts ...RegisterOption) // 注册采集器
+	Start(ctx context.Context) error                      // 初始化采集器并启动采集（定时器循环），必需采集器初始化失败时返回错误
+	Shutdown(ctx context.Context) error                   // 优雅停止
+	CollectAll(ctx context.Context) error                 // 立即采集所有采集器（退出前的最后一次采集）
+
+	// 运行时管理（/api/v1/collectors）
+	Collectors() []CollectorStatus                           // 所有采集器的状态
+	Collector(name string) (CollectorStatus, error)          // 单个采集器的状态
+	SetCollectorEnabled(name string, enabled bool) error     // 启用/停用采集器
+	TriggerCollector(ctx context.Context, name string) error // 立即采集一次
 }
 
 // Collector 采集器核心接口（所有采集器必须实现）
@@ -17,3 +27,10 @@ type Collector interface {
 	Collect(ctx context.Context) error // 采集数据（更新指标）
 	Close() error                      // 关闭（释放资源）
 }
+
+// Scheduled 可选接口：采集器声明自己的默认采集间隔和超时（返回 0 表示使用全局 interval）
+// 配置文件 metrics.schedules 中按配置键（如 proc）或采集器名称（如 cpu-collector）配置的值优先
+// 超时比采集间隔长时采集间隔会被提高到超时
+type Scheduled interface {
+	Schedule() (interval, timeout time.Duration)
+}
@@ -0,0 +1,26 @@
+package registers
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/agent-collector/pkg/config"
+	"github.com/agent-collector/pkg/logger"
+)
+
+// TestMain 调度器依赖全局 logger，测试前先初始化到临时目录
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "registers-test-logs")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if _, err := logger.InitLogger(&config.ZapLogConfig{Level: "error", Format: "json", Path: dir}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
@@ -0,0 +1,130 @@
+package registers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/agent-collector/pkg/logger"
+	"github.com/agent-collector/pkg/procfs"
+	"go.uber.org/zap"
+)
+
+// CollectorStatus 采集器运行状态（管理接口 /api/v1/collectors 的返回内容）
+type CollectorStatus struct {
+	Name                string         `json:"name"`
+	State               CollectorState `json:"state"`
+	Interval            string         `json:"interval"`
+	Timeout             string         `json:"timeout"`
+	Offset              string         `json:"offset"`
+	Required            bool           `json:"required"`
+	Running             bool           `json:"running"`
+	LastRun             *time.Time     `json:"last_run,omitempty"`
+	LastDuration        string         `json:"last_duration,omitempty"`
+	LastError           string         `json:"last_error,omitempty"`
+	ConsecutiveFailures int            `json:"consecutive_failures"`
+}
+
+// Collectors 返回所有已注册采集器的状态（按注册顺序）
+func (r *AgentImpl) Collectors() []CollectorStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]CollectorStatus, 0, len(r.collectors))
+	for _, sc := range r.collectors {
+		out = append(out, sc.status())
+	}
+	return out
+}
+
+// Collector 返回指定采集器的状态
+func (r *AgentImpl) Collector(name string) (CollectorStatus, error) {
+	sc, err := r.find(name)
+	if err != nil {
+		return CollectorStatus{}, err
+	}
+	return sc.status(), nil
+}
+
+// SetCollectorEnabled 运行时启用/停用采集器：停用后调度器和 scrape 都不再调用它的 Collect（正在进行的采集不受影响），
+// 启用后清空连续失败次数并立即回到正常调度
+func (r *AgentImpl) SetCollectorEnabled(name string, enabled bool) error {
+	sc, err := r.find(name)
+	if err != nil {
+		return err
+	}
+	from, to := sc.health.setEnabled(enabled)
+	if from == to {
+		return nil
+	}
+	r.transition(sc.Name(), from, to)
+	if enabled {
+		r.health.failures.WithLabelValues(sc.Name()).Set(0)
+	}
+	logger.Info("collector state changed by operator", zap.String("name", sc.Name()),
+		zap.String("from", string(from)), zap.String("to", string(to)))
+	return nil
+}
+
+// TriggerCollector 立即执行一次采集并等待结束，不影响正常调度；停用、尚未初始化成功、正在采集或已关闭时返回错误
+// 触发的采集与调度启动的采集一样计入 inflight，Shutdown 会等它结束后再关闭采集器
+func (r *AgentImpl) TriggerCollector(ctx context.Context, name string) error {
+	sc, err := r.find(name)
+	if err != nil {
+		return err
+	}
+	if err := sc.unavailable(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	if r.ctx.Err() != nil {
+		r.mu.Unlock()
+		return fmt.Errorf("%s: %w", name, ErrAgentStopped)
+	}
+	r.inflight.Add(1)
+	r.mu.Unlock()
+	defer r.inflight.Done()
+
+	if !sc.running.CompareAndSwap(false, true) {
+		return fmt.Errorf("%s: %w", name, ErrCollectorBusy)
+	}
+	snapshot := procfs.NewSnapshot(r.fs)
+	defer snapshot.Release()
+	return r.collectOne(procfs.WithSnapshot(ctx, snapshot), sc)
+}
+
+// find 按名称查找采集器
+func (r *AgentImpl) find(name string) (*scheduledCollector, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sc := range r.collectors {
+		if sc.Name() == name {
+			return sc, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", name, ErrCollectorNotFound)
+}
+
+// status 汇总采集器的调度参数和健康状态
+func (sc *scheduledCollector) status() CollectorStatus {
+	sc.health.mu.Lock()
+	defer sc.health.mu.Unlock()
+	s := CollectorStatus{
+		Name:                sc.Name(),
+		State:               sc.health.state,
+		Interval:            sc.interval.String(),
+		Timeout:             sc.timeout.String(),
+		Offset:              sc.offset.String(),
+		Required:            sc.required,
+		Running:             sc.running.Load(),
+		ConsecutiveFailures: sc.health.failures,
+	}
+	if !sc.health.lastRun.IsZero() {
+		lastRun := sc.health.lastRun
+		s.LastRun = &lastRun
+		s.LastDuration = sc.health.lastDuration.String()
+	}
+	if sc.health.lastErr != nil {
+		s.LastError = sc.health.lastErr.Error()
+	}
+	return s
+}
@@ -1,27 +1,21 @@
 package registers
 
 import (
-	"context"
 	"fmt"
-	"github.com/agent-collector/pkg/collector"
 	"github.com/agent-collector/pkg/config"
 	"github.com/agent-collector/pkg/logger"
 	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	"sort"
 )
 
-type Module struct {
-	Enabled bool
-	Name    string
-	NewFunc func() Collector
-}
-
 // InitPromRegistry 返回值
 // promReg	*prometheus.Registry	Prometheus 指标注册器，可用于 HTTP endpoint 暴露 metrics 或做单元测试
-// agent	Agent	                采集器管理器，后台周期性调用已注册的采集器进行指标采集
+// agent	Agent	                采集器管理器（已注册采集器、尚未启动），由调用方调用 Start/Shutdown（见 pkg/lifecycle）
 // nil	    error	                初始化成功时返回 nil，如果初始化或注册失败则返回具体错误
-func InitPromRegistry(ctx context.Context, enableProcess bool, cfg *config.Config) (*prometheus.Registry, Agent, error) {
+func InitPromRegistry(enableProcess bool, cfg *config.Config) (*prometheus.Registry, Agent, error) {
 	// 3. 初始化Prometheus指标注册器（禁用Go指标）
 	promReg := prometheus.NewRegistry()
 	// 仅注册进程指标（可选），不注册Go指标
@@ -29,90 +23,87 @@ func InitPromRegistry(ctx context.Context, enableProcess bool, cfg *config.Confi
 		promReg.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
 	}
 
+	// 宿主机挂载点：所有采集器和 gopsutil 都从这里读取 procfs/sysfs
+	procfs.Configure(cfg.Path.ProcFS, cfg.Path.SysFS, cfg.Path.RootFS)
+
 	// 初始化工厂包装成自己的 Registry
-	metricFactory := metrics.NewMetricFactory(metrics.NewPromRegistry(promReg))
+	// scrape 模式下采集器指标先注册到 CollectorSet，由 ScrapeCollector 在 scrape 时触发采集后统一输出
+	var metricSet *metrics.CollectorSet
+	var metricReg metrics.Registers = metrics.NewPromRegistry(promReg)
+	if cfg.Monitor.Mode == ModeScrape {
+		metricSet = metrics.NewCollectorSet()
+		metricReg = metricSet
+	}
+	metricFactory := metrics.NewMetricFactory(metricReg)
+	metricFactory.SeriesTracker().SetStaleCycles(cfg.Monitor.StaleCycles)
+
+	// 采集器通过 FS 读取宿主机文件，测试中可替换为 fixture 目录或内存文件系统
+	fsys := procfs.OSFS{}
 
 	//	// 4. 初始化采集器Agent（依赖接口）
-	agent := NewRegistry(cfg.Monitor.Interval)
+	agent := NewRegistry(&cfg.Monitor, *metricFactory, fsys)
 
 	// 5. 注册采集器（统一入口，扩展仅需添加注册代码）
-	registeredCollectors, err := RegisterCollectors(agent, cfg, *metricFactory)
-	// 新增调试日志：打印所有采集器的启用状态
-	logger.Debug("collector enable status",
-		zap.Bool("proc_enable", cfg.Monitor.Collectors.Proc.Enable),
-		zap.Bool("sys_enable", cfg.Monitor.Collectors.Sys.Enable),
-		zap.Bool("cgroup_enable", cfg.Monitor.Collectors.Cgroup.Enable),
-		zap.Bool("container_enable", cfg.Monitor.Collectors.Container.Enable),
-	)
+	registeredCollectors, err := RegisterCollectors(agent, cfg, *metricFactory, fsys)
 	if err != nil {
 		logger.Error("failed to register collectors", zap.Error(err))
 		return nil, nil, err
 	}
-
-	// 5. 调用Agent.Start（传入正确的Collector实例，无类型错误）
-	agent.Start(ctx)
+	if metricSet != nil {
+		promReg.MustRegister(NewScrapeCollector(agent, metricSet, cfg.Monitor.ScrapeMinAge))
+	}
 
 	logger.Debug("failed to register collectors", zap.String("name", registeredCollectors[0].Name()), zap.Int("first_collector", len(registeredCollectors)), zap.Duration("interval", cfg.Monitor.Interval))
 
 	return promReg, agent, nil
 }
 
-// RegisterCollectors  采集器注册统一入口（扩展仅需修改此函数）核心：开关控制 + 标识选择）
-// 循环注册
-// 新增采集器只需在 modules 列表添加一条，不必写重复的 if/else。
-// 日志结构化
-// zap.String、zap.Strings 保证结构化日志规范。
-// 返回所有已注册采集器
-// 避免单一 targetCollector 覆盖问题。
-// 可扩展性强
-// 支持 /proc、/sys、Cgroup、Container，未来添加新的数据源只需要新增一条 module 配置即可。
-func RegisterCollectors(agent Agent, cfg *config.Config, metricFactory metrics.MetricFactory) ([]Collector, error) {
+// RegisterCollectors 按 cfg.Monitor.Collectors 创建并注册所有启用的采集器，返回已注册的采集器
+// 采集器在各自包的 init() 中通过 RegisterFactory 注册工厂，新增采集器无需修改此函数；
+// collectors.<name>.enable 未配置时使用工厂的 DefaultEnabled，collectors.<name>.required 为 true 时注册为必需采集器，
+// 其余配置项解码到工厂自己的配置结构体
+func RegisterCollectors(agent Agent, cfg *config.Config, metricFactory metrics.MetricFactory, fsys procfs.FS) ([]Collector, error) {
+	deps := Deps{MetricFactory: metricFactory, FS: fsys}
 
-	modu := []Module{
-		{
-			Enabled: cfg.Monitor.Collectors.Proc.Enable,
-			Name:    "/proc",
-			NewFunc: func() Collector {
-				return collector.NewCPUCollector(&cfg.Monitor.Collectors, metricFactory)
-			},
-		},
-		//{
-		//	enabled: cfg.Monitor.Collectors.Sys.Enable,
-		//	name:    "/sys",
-		//	newFunc: func() Collector {
-		//		return collector.NewSysCollector(cfg.Sys.IgnoreDisks, cfg.Sys.IgnoreNetworks, metricFactory)
-		//	},
-		//},
-		//{
-		//	enabled: cfg.Cgroup.Enable,
-		//	name:    "cgroup",
-		//	newFunc: func() collector2.Collector {
-		//		return collector2.NewCgroupCollector(metricFactory)
-		//	},
-		//},
-		//{
-		//	enabled: cfg.Container.Enable,
-		//	name:    "container",
-		//	newFunc: func() collector2.Collector {
-		//		return collector2.NewContainerCollector(metricFactory)
-		//	},
-		//},
+	// 配置中出现但没有对应工厂的名称只告警，兼容旧配置文件（如尚未实现的 sys/cgroup）
+	for name := range cfg.Monitor.Collectors {
+		if _, ok := LookupFactory(name); !ok {
+			logger.Warn("unknown collector in config, ignored", zap.String("name", name))
+		}
 	}
 
 	var registered []Collector
-	for _, m := range modu {
-		if m.Enabled {
-			c := m.NewFunc()
-			agent.Register(c)
-			registered = append(registered, c)
-			logger.Debug("registered collector", zap.String("name", m.Name))
-		} else {
-			logger.Debug("collector disabled", zap.String("name", m.Name))
+	for _, f := range Factories() {
+		raw := cfg.Monitor.Collectors[f.Name]
+		enabled, err := f.Enabled(raw)
+		if err != nil {
+			return nil, err
+		}
+		if !enabled {
+			logger.Debug("collector disabled", zap.String("name", f.Name))
+			continue
 		}
+		required, err := f.Required(raw)
+		if err != nil {
+			return nil, err
+		}
+		c, err := f.Build(raw, deps)
+		if err != nil {
+			return nil, err
+		}
+		opts := []RegisterOption{ConfigKey(f.Name)}
+		if required {
+			opts = append(opts, Required())
+		}
+		agent.Register(c, opts...)
+		registered = append(registered, c)
+		logger.Debug("registered collector", zap.String("name", f.Name))
 	}
 	if len(registered) == 0 {
-		return nil, fmt.Errorf("no collectors enabled; check your CollectorConfig")
-
+		return nil, fmt.Errorf("no collectors enabled; set collectors.<name>.enable for at least one collector")
+	}
+	for _, name := range unknownSchedules(cfg.Monitor.Schedules, registered) {
+		logger.Warn("unknown collector in metrics.schedules, ignored", zap.String("name", name))
 	}
 	// 日志输出所有已启用的采集器（便于排查配置）
 	var names []string
@@ -122,5 +113,21 @@ func RegisterCollectors(agent Agent, cfg *config.Config, metricFactory metrics.M
 	logger.Debug("all enabled collectors registered", zap.Strings("enabled_collectors", names))
 
 	return registered, nil
+}
 
+// unknownSchedules 返回 metrics.schedules 中既不是工厂名称、也不是已启用采集器名称的键（拼写错误或采集器未启用），按名称排序
+func unknownSchedules(schedules map[string]config.ScheduleConfig, registered []Collector) []string {
+	names := make(map[string]bool, len(registered))
+	for _, c := range registered {
+		names[c.Name()] = true
+	}
+	var unknown []string
+	for key := range schedules {
+		if _, ok := LookupFactory(key); ok || names[key] {
+			continue
+		}
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	return unknown
 }
@@ -0,0 +1,67 @@
+package registers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/agent-collector/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// 采集模式（metrics.mode）
+const (
+	ModePush   = "push"   // 调度协程按 interval 定时采集，指标值常驻内存（也用于转发到远端）
+	ModeScrape = "scrape" // 收到 scrape 请求时才采集，没人 scrape 时不产生采集开销
+)
+
+// ScrapeCollector collect-on-scrape 模式下注册到对外 registry 的 prometheus.Collector
+// Collect 被 promhttp 调用时先触发一次全部采集器的采集，再输出 CollectorSet 中的指标；
+// 上一次采集距今不足 minAge 时直接输出缓存的指标值，并发的 scrape 排队等待同一次采集结果，不会成倍增加采集开销
+// 属于 unchecked collector：指标集合随采集器动态变化（如 textfile、exec 的外部指标），Describe 不输出描述
+type ScrapeCollector struct {
+	agent   *AgentImpl
+	metrics prometheus.Collector
+	minAge  time.Duration
+
+	mu   sync.Mutex
+	last time.Time // 上一次采集完成时间
+}
+
+// NewScrapeCollector 创建 scrape 触发采集的 Collector，metrics 为采集器指标注册到的 CollectorSet
+func NewScrapeCollector(agent *AgentImpl, metrics prometheus.Collector, minAge time.Duration) *ScrapeCollector {
+	return &ScrapeCollector{agent: agent, metrics: metrics, minAge: minAge}
+}
+
+// Describe 实现 prometheus.Collector，unchecked collector 不输出描述
+func (s *ScrapeCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect 实现 prometheus.Collector：按需采集后输出全部指标
+func (s *ScrapeCollector) Collect(ch chan<- prometheus.Metric) {
+	s.refresh()
+	s.metrics.Collect(ch)
+}
+
+// refresh 数据超过 minAge 时采集一次；采集失败只记录日志，仍然输出已有的指标值
+// 采集登记到 inflight，Shutdown 等它结束后才关闭采集器；Shutdown 开始后不再采集，直接输出缓存的指标值
+func (s *ScrapeCollector) refresh() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.last.IsZero() && time.Since(s.last) < s.minAge {
+		return
+	}
+	r := s.agent
+	r.mu.Lock()
+	if r.ctx.Err() != nil {
+		r.mu.Unlock()
+		return
+	}
+	r.inflight.Add(1)
+	r.mu.Unlock()
+	defer r.inflight.Done()
+
+	if err := r.collectNow(r.ctx, true); err != nil {
+		logger.Warn("collection on scrape failed", zap.String("name", "collector-registry"), zap.Error(err))
+	}
+	s.last = time.Now()
+}
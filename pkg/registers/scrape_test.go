@@ -0,0 +1,139 @@
+package registers
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agent-collector/pkg/config"
+	"github.com/agent-collector/pkg/metrics"
+	"github.com/agent-collector/pkg/procfs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestScrapeCollectorMinAge(t *testing.T) {
+	set := metrics.NewCollectorSet()
+	factory := *metrics.NewMetricFactory(set)
+	agent := NewRegistry(&config.MonitorConfig{
+		Interval: 10 * time.Second,
+		Mode:     ModeScrape,
+		Workers:  2,
+		Backoff:  config.BackoffConfig{Threshold: 3, Initial: time.Second, Max: time.Minute},
+	}, factory, procfs.OSFS{})
+	fake := &fakeCollector{name: "fake", delay: 20 * time.Millisecond}
+	agent.Register(fake)
+	if err := agent.Start(t.Context()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	reg := prometheus.NewRegistry()
+	sc := NewScrapeCollector(agent, set, 200*time.Millisecond)
+	reg.MustRegister(sc)
+
+	// 并发 scrape 共享同一次采集
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := reg.Gather(); err != nil {
+				t.Errorf("gather: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := fake.calls.Load(); n != 1 {
+		t.Fatalf("collect calls after concurrent scrapes = %d, want 1", n)
+	}
+	// 采集器指标通过 CollectorSet 输出
+	if n := testutil.CollectAndCount(sc, "agent_collector_state"); n != len(collectorStates) {
+		t.Errorf("agent_collector_state series = %d, want %d", n, len(collectorStates))
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	if _, err := reg.Gather(); err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	if n := fake.calls.Load(); n != 2 {
+		t.Errorf("collect calls after min age = %d, want 2", n)
+	}
+}
+
+// closeTracker 记录 Close 是否发生在 Collect 结束之前
+type closeTracker struct {
+	fakeCollector
+	collecting  atomic.Bool
+	closedEarly atomic.Bool
+}
+
+func (c *closeTracker) Collect(ctx context.Context) error {
+	c.collecting.Store(true)
+	defer c.collecting.Store(false)
+	return c.fakeCollector.Collect(ctx)
+}
+
+func (c *closeTracker) Close() error {
+	if c.collecting.Load() {
+		c.closedEarly.Store(true)
+	}
+	return nil
+}
+
+// TestScrapeCollectionWaitedByShutdown scrape 触发的采集进行中时 Shutdown 等它结束才关闭采集器，
+// Shutdown 之后的 scrape 只输出缓存的指标值
+func TestScrapeCollectionWaitedByShutdown(t *testing.T) {
+	set := metrics.NewCollectorSet()
+	factory := *metrics.NewMetricFactory(set)
+	agent := NewRegistry(&config.MonitorConfig{
+		Interval: 10 * time.Second,
+		Mode:     ModeScrape,
+		Workers:  1,
+		Backoff:  config.BackoffConfig{Threshold: 3, Initial: time.Second, Max: time.Minute},
+	}, factory, procfs.OSFS{})
+	c := &closeTracker{fakeCollector: fakeCollector{name: "slow", delay: 200 * time.Millisecond}}
+	agent.Register(c)
+	if err := agent.Start(t.Context()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewScrapeCollector(agent, set, 0))
+
+	scraped := make(chan error, 1)
+	go func() {
+		_, err := reg.Gather()
+		scraped <- err
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for c.calls.Load() < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("collection on scrape did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := agent.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if c.collecting.Load() {
+		t.Error("Shutdown returned before the collection on scrape finished")
+	}
+	if err := <-scraped; err != nil {
+		t.Errorf("gather: %v", err)
+	}
+	if c.closedEarly.Load() {
+		t.Error("collector closed while the collection on scrape was running")
+	}
+
+	if n := testutil.CollectAndCount(set, "agent_collector_state"); n == 0 {
+		t.Error("no cached metrics after Shutdown")
+	}
+	if _, err := reg.Gather(); err != nil {
+		t.Fatalf("gather after Shutdown: %v", err)
+	}
+	if n := c.calls.Load(); n != 1 {
+		t.Errorf("collect calls = %d, want no collection after Shutdown", n)
+	}
+}